@@ -0,0 +1,508 @@
+package compat
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	csiv0 "github.com/thecodeteam/gocsi/csi"
+)
+
+// V0ToV1ControllerServer adapts a CSI 0.x ControllerServer so that it
+// satisfies the CSI v1.x ControllerServer interface, translating the
+// request/response types for the RPCs the two spec versions share.
+// RPCs introduced in v1.x with no v0.x equivalent (GetCapacity,
+// snapshots, volume expansion) return codes.Unimplemented.
+func V0ToV1ControllerServer(v0 csiv0.ControllerServer) csi.ControllerServer {
+	return &v0ToV1ControllerServer{v0: v0}
+}
+
+type v0ToV1ControllerServer struct {
+	v0 csiv0.ControllerServer
+}
+
+func (s *v0ToV1ControllerServer) CreateVolume(
+	ctx context.Context,
+	req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+
+	v0req := &csiv0.CreateVolumeRequest{
+		Name:               req.Name,
+		VolumeCapabilities: toV0Capabilities(req.VolumeCapabilities),
+		Parameters:         req.Parameters,
+		UserCredentials:    toV0Secrets(req.Secrets),
+	}
+	if cr := req.CapacityRange; cr != nil {
+		v0req.CapacityRange = &csiv0.CapacityRange{
+			RequiredBytes: uint64(cr.RequiredBytes),
+			LimitBytes:    uint64(cr.LimitBytes),
+		}
+	}
+
+	v0rep, err := s.v0.CreateVolume(ctx, v0req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csi.CreateVolumeResponse{
+		Volume: toV1Volume(v0rep.VolumeInfo),
+	}, nil
+}
+
+func (s *v0ToV1ControllerServer) DeleteVolume(
+	ctx context.Context,
+	req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
+
+	_, err := s.v0.DeleteVolume(ctx, &csiv0.DeleteVolumeRequest{
+		VolumeId:        req.VolumeId,
+		UserCredentials: toV0Secrets(req.Secrets),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &csi.DeleteVolumeResponse{}, nil
+}
+
+func (s *v0ToV1ControllerServer) ControllerPublishVolume(
+	ctx context.Context,
+	req *csi.ControllerPublishVolumeRequest,
+) (*csi.ControllerPublishVolumeResponse, error) {
+
+	v0rep, err := s.v0.ControllerPublishVolume(
+		ctx, &csiv0.ControllerPublishVolumeRequest{
+			VolumeId:         req.VolumeId,
+			NodeId:           req.NodeId,
+			VolumeCapability: toV0Capability(req.VolumeCapability),
+			Readonly:         req.Readonly,
+			UserCredentials:  toV0Secrets(req.Secrets),
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerPublishVolumeResponse{
+		PublishContext: v0rep.PublishVolumeInfo,
+	}, nil
+}
+
+func (s *v0ToV1ControllerServer) ControllerUnpublishVolume(
+	ctx context.Context,
+	req *csi.ControllerUnpublishVolumeRequest,
+) (*csi.ControllerUnpublishVolumeResponse, error) {
+
+	_, err := s.v0.ControllerUnpublishVolume(
+		ctx, &csiv0.ControllerUnpublishVolumeRequest{
+			VolumeId:        req.VolumeId,
+			NodeId:          req.NodeId,
+			UserCredentials: toV0Secrets(req.Secrets),
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerUnpublishVolumeResponse{}, nil
+}
+
+func (s *v0ToV1ControllerServer) ListVolumes(
+	ctx context.Context,
+	req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+
+	v0rep, err := s.v0.ListVolumes(ctx, &csiv0.ListVolumesRequest{
+		MaxEntries:    req.MaxEntries,
+		StartingToken: req.StartingToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*csi.ListVolumesResponse_Entry, len(v0rep.Entries))
+	for i, e := range v0rep.Entries {
+		entries[i] = &csi.ListVolumesResponse_Entry{
+			Volume: toV1Volume(e.VolumeInfo),
+		}
+	}
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: v0rep.NextToken,
+	}, nil
+}
+
+func (s *v0ToV1ControllerServer) ValidateVolumeCapabilities(
+	ctx context.Context,
+	req *csi.ValidateVolumeCapabilitiesRequest,
+) (*csi.ValidateVolumeCapabilitiesResponse, error) {
+
+	v0rep, err := s.v0.ValidateVolumeCapabilities(
+		ctx, &csiv0.ValidateVolumeCapabilitiesRequest{
+			VolumeId:           req.VolumeId,
+			VolumeCapabilities: toV0Capabilities(req.VolumeCapabilities),
+		})
+	if err != nil {
+		return nil, err
+	}
+	return toV1ValidateVolumeCapabilitiesResponse(v0rep, req), nil
+}
+
+func (s *v0ToV1ControllerServer) GetCapacity(
+	ctx context.Context,
+	req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"GetCapacity has no CSI 0.x equivalent")
+}
+
+func (s *v0ToV1ControllerServer) ControllerGetCapabilities(
+	ctx context.Context,
+	req *csi.ControllerGetCapabilitiesRequest,
+) (*csi.ControllerGetCapabilitiesResponse, error) {
+
+	v0rep, err := s.v0.ControllerGetCapabilities(
+		ctx, &csiv0.ControllerGetCapabilitiesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerGetCapabilitiesResponse{
+		Capabilities: toV1ControllerCapabilities(v0rep.Capabilities),
+	}, nil
+}
+
+func (s *v0ToV1ControllerServer) CreateSnapshot(
+	ctx context.Context,
+	req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"CreateSnapshot has no CSI 0.x equivalent")
+}
+
+func (s *v0ToV1ControllerServer) DeleteSnapshot(
+	ctx context.Context,
+	req *csi.DeleteSnapshotRequest) (*csi.DeleteSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"DeleteSnapshot has no CSI 0.x equivalent")
+}
+
+func (s *v0ToV1ControllerServer) ListSnapshots(
+	ctx context.Context,
+	req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"ListSnapshots has no CSI 0.x equivalent")
+}
+
+func (s *v0ToV1ControllerServer) ControllerExpandVolume(
+	ctx context.Context,
+	req *csi.ControllerExpandVolumeRequest,
+) (*csi.ControllerExpandVolumeResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"ControllerExpandVolume has no CSI 0.x equivalent")
+}
+
+func toV1Volume(v *csiv0.VolumeInfo) *csi.Volume {
+	if v == nil {
+		return nil
+	}
+	return &csi.Volume{
+		CapacityBytes: int64(v.CapacityBytes),
+		VolumeId:      v.Id,
+		VolumeContext: v.Attributes,
+	}
+}
+
+// toV1ValidateVolumeCapabilitiesResponse translates a v0.x
+// ValidateVolumeCapabilitiesResponse into its v1.x equivalent, mirroring
+// the mock service's own behavior (see mock/service/controller.go's
+// ValidateVolumeCapabilities): a v0.x response with Supported set to
+// true is reported as confirming the capabilities the caller asked
+// about, while an unsupported response leaves Confirmed nil.
+func toV1ValidateVolumeCapabilitiesResponse(
+	v0rep *csiv0.ValidateVolumeCapabilitiesResponse,
+	req *csi.ValidateVolumeCapabilitiesRequest,
+) *csi.ValidateVolumeCapabilitiesResponse {
+
+	rep := &csi.ValidateVolumeCapabilitiesResponse{
+		Message: v0rep.Message,
+	}
+	if v0rep.Supported {
+		rep.Confirmed = &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
+			VolumeContext:      req.VolumeContext,
+			VolumeCapabilities: req.VolumeCapabilities,
+			Parameters:         req.Parameters,
+		}
+	}
+	return rep
+}
+
+// toV1ControllerCapabilities translates the RPC capabilities a v0.x
+// plugin reports via ControllerGetCapabilities into their v1.x
+// equivalents; the RPC_Type enums the two spec versions share (the
+// only ones a v0.x plugin can report) use identical values, so each
+// capability converts directly rather than through a lookup table.
+func toV1ControllerCapabilities(
+	v0caps []*csiv0.ControllerServiceCapability,
+) []*csi.ControllerServiceCapability {
+
+	caps := make([]*csi.ControllerServiceCapability, 0, len(v0caps))
+	for _, c := range v0caps {
+		rpc := c.GetRpc()
+		if rpc == nil {
+			continue
+		}
+		caps = append(caps, &csi.ControllerServiceCapability{
+			Type: &csi.ControllerServiceCapability_Rpc{
+				Rpc: &csi.ControllerServiceCapability_RPC{
+					Type: csi.ControllerServiceCapability_RPC_Type(rpc.Type),
+				},
+			},
+		})
+	}
+	return caps
+}
+
+func toV0Capability(c *csi.VolumeCapability) *csiv0.VolumeCapability {
+	if c == nil {
+		return nil
+	}
+	v0c := &csiv0.VolumeCapability{}
+	if am := c.AccessMode; am != nil {
+		v0c.AccessMode = &csiv0.VolumeCapability_AccessMode{
+			Mode: csiv0.VolumeCapability_AccessMode_Mode(am.Mode),
+		}
+	}
+	switch t := c.AccessType.(type) {
+	case *csi.VolumeCapability_Block:
+		v0c.AccessType = &csiv0.VolumeCapability_Block{
+			Block: &csiv0.VolumeCapability_BlockVolume{},
+		}
+	case *csi.VolumeCapability_Mount:
+		v0c.AccessType = &csiv0.VolumeCapability_Mount{
+			Mount: &csiv0.VolumeCapability_MountVolume{
+				FsType:     t.Mount.FsType,
+				MountFlags: t.Mount.MountFlags,
+			},
+		}
+	}
+	return v0c
+}
+
+func toV0Capabilities(
+	cs []*csi.VolumeCapability) []*csiv0.VolumeCapability {
+
+	if cs == nil {
+		return nil
+	}
+	v0cs := make([]*csiv0.VolumeCapability, len(cs))
+	for i, c := range cs {
+		v0cs[i] = toV0Capability(c)
+	}
+	return v0cs
+}
+
+// toV0Secrets adapts a v1.x Secrets map to the single v0.x
+// UserCredentials map; the two are wire-compatible string maps, so no
+// translation of the values themselves is required.
+func toV0Secrets(s map[string]string) map[string]string {
+	return s
+}
+
+// ProbeAndNegotiate issues GetPluginCapabilities against cc and returns a ControllerClient built against the spec version the
+// plugin actually supports: a v1.x client is returned as-is, while a
+// plugin that only understands CSI 0.x capabilities is wrapped with
+// V1ToV0ControllerClient so callers can speak v1.x regardless of which
+// generation of plugin answers the connection.
+func ProbeAndNegotiate(
+	ctx context.Context, cc *grpc.ClientConn) (csi.ControllerClient, error) {
+
+	ic := csi.NewIdentityClient(cc)
+	_, err := ic.GetPluginCapabilities(ctx, &csi.GetPluginCapabilitiesRequest{})
+	switch status.Code(err) {
+	case codes.OK:
+		return csi.NewControllerClient(cc), nil
+	case codes.Unimplemented:
+		return V1ToV0ControllerClient(csiv0.NewControllerClient(cc)), nil
+	default:
+		return nil, err
+	}
+}
+
+// V1ToV0ControllerClient adapts a CSI 0.x ControllerClient so that it
+// satisfies the CSI v1.x ControllerClient interface, allowing v1.x
+// callers such as csc to keep talking to older plugins.
+func V1ToV0ControllerClient(v0 csiv0.ControllerClient) csi.ControllerClient {
+	return &v1ToV0ControllerClient{v0: v0}
+}
+
+type v1ToV0ControllerClient struct {
+	v0 csiv0.ControllerClient
+}
+
+func (c *v1ToV0ControllerClient) CreateVolume(
+	ctx context.Context,
+	req *csi.CreateVolumeRequest,
+	opts ...grpc.CallOption) (*csi.CreateVolumeResponse, error) {
+
+	v0req := &csiv0.CreateVolumeRequest{
+		Name:               req.Name,
+		VolumeCapabilities: toV0Capabilities(req.VolumeCapabilities),
+		Parameters:         req.Parameters,
+		UserCredentials:    toV0Secrets(req.Secrets),
+	}
+	if cr := req.CapacityRange; cr != nil {
+		v0req.CapacityRange = &csiv0.CapacityRange{
+			RequiredBytes: uint64(cr.RequiredBytes),
+			LimitBytes:    uint64(cr.LimitBytes),
+		}
+	}
+
+	v0rep, err := c.v0.CreateVolume(ctx, v0req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.CreateVolumeResponse{
+		Volume: toV1Volume(v0rep.VolumeInfo),
+	}, nil
+}
+
+func (c *v1ToV0ControllerClient) DeleteVolume(
+	ctx context.Context,
+	req *csi.DeleteVolumeRequest,
+	opts ...grpc.CallOption) (*csi.DeleteVolumeResponse, error) {
+
+	_, err := c.v0.DeleteVolume(ctx, &csiv0.DeleteVolumeRequest{
+		VolumeId:        req.VolumeId,
+		UserCredentials: toV0Secrets(req.Secrets),
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.DeleteVolumeResponse{}, nil
+}
+
+func (c *v1ToV0ControllerClient) ListVolumes(
+	ctx context.Context,
+	req *csi.ListVolumesRequest,
+	opts ...grpc.CallOption) (*csi.ListVolumesResponse, error) {
+
+	v0rep, err := c.v0.ListVolumes(ctx, &csiv0.ListVolumesRequest{
+		MaxEntries:    req.MaxEntries,
+		StartingToken: req.StartingToken,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*csi.ListVolumesResponse_Entry, len(v0rep.Entries))
+	for i, e := range v0rep.Entries {
+		entries[i] = &csi.ListVolumesResponse_Entry{
+			Volume: toV1Volume(e.VolumeInfo),
+		}
+	}
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: v0rep.NextToken,
+	}, nil
+}
+
+func (c *v1ToV0ControllerClient) ControllerPublishVolume(
+	ctx context.Context,
+	req *csi.ControllerPublishVolumeRequest,
+	opts ...grpc.CallOption) (*csi.ControllerPublishVolumeResponse, error) {
+
+	v0rep, err := c.v0.ControllerPublishVolume(
+		ctx, &csiv0.ControllerPublishVolumeRequest{
+			VolumeId:         req.VolumeId,
+			NodeId:           req.NodeId,
+			VolumeCapability: toV0Capability(req.VolumeCapability),
+			Readonly:         req.Readonly,
+			UserCredentials:  toV0Secrets(req.Secrets),
+		}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerPublishVolumeResponse{
+		PublishContext: v0rep.PublishVolumeInfo,
+	}, nil
+}
+
+func (c *v1ToV0ControllerClient) ControllerUnpublishVolume(
+	ctx context.Context,
+	req *csi.ControllerUnpublishVolumeRequest,
+	opts ...grpc.CallOption) (*csi.ControllerUnpublishVolumeResponse, error) {
+
+	_, err := c.v0.ControllerUnpublishVolume(
+		ctx, &csiv0.ControllerUnpublishVolumeRequest{
+			VolumeId:        req.VolumeId,
+			NodeId:          req.NodeId,
+			UserCredentials: toV0Secrets(req.Secrets),
+		}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerUnpublishVolumeResponse{}, nil
+}
+
+func (c *v1ToV0ControllerClient) ValidateVolumeCapabilities(
+	ctx context.Context,
+	req *csi.ValidateVolumeCapabilitiesRequest,
+	opts ...grpc.CallOption) (*csi.ValidateVolumeCapabilitiesResponse, error) {
+
+	v0rep, err := c.v0.ValidateVolumeCapabilities(
+		ctx, &csiv0.ValidateVolumeCapabilitiesRequest{
+			VolumeId:           req.VolumeId,
+			VolumeCapabilities: toV0Capabilities(req.VolumeCapabilities),
+		}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return toV1ValidateVolumeCapabilitiesResponse(v0rep, req), nil
+}
+
+func (c *v1ToV0ControllerClient) GetCapacity(
+	ctx context.Context,
+	req *csi.GetCapacityRequest,
+	opts ...grpc.CallOption) (*csi.GetCapacityResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"GetCapacity has no CSI 0.x equivalent")
+}
+
+func (c *v1ToV0ControllerClient) ControllerGetCapabilities(
+	ctx context.Context,
+	req *csi.ControllerGetCapabilitiesRequest,
+	opts ...grpc.CallOption) (*csi.ControllerGetCapabilitiesResponse, error) {
+
+	v0rep, err := c.v0.ControllerGetCapabilities(
+		ctx, &csiv0.ControllerGetCapabilitiesRequest{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.ControllerGetCapabilitiesResponse{
+		Capabilities: toV1ControllerCapabilities(v0rep.Capabilities),
+	}, nil
+}
+
+func (c *v1ToV0ControllerClient) CreateSnapshot(
+	ctx context.Context,
+	req *csi.CreateSnapshotRequest,
+	opts ...grpc.CallOption) (*csi.CreateSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"CreateSnapshot has no CSI 0.x equivalent")
+}
+
+func (c *v1ToV0ControllerClient) DeleteSnapshot(
+	ctx context.Context,
+	req *csi.DeleteSnapshotRequest,
+	opts ...grpc.CallOption) (*csi.DeleteSnapshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"DeleteSnapshot has no CSI 0.x equivalent")
+}
+
+func (c *v1ToV0ControllerClient) ListSnapshots(
+	ctx context.Context,
+	req *csi.ListSnapshotsRequest,
+	opts ...grpc.CallOption) (*csi.ListSnapshotsResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"ListSnapshots has no CSI 0.x equivalent")
+}
+
+func (c *v1ToV0ControllerClient) ControllerExpandVolume(
+	ctx context.Context,
+	req *csi.ControllerExpandVolumeRequest,
+	opts ...grpc.CallOption) (*csi.ControllerExpandVolumeResponse, error) {
+	return nil, status.Error(codes.Unimplemented,
+		"ControllerExpandVolume has no CSI 0.x equivalent")
+}
@@ -0,0 +1,404 @@
+package compat
+
+import (
+	"net"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	csiv0 "github.com/thecodeteam/gocsi/csi"
+)
+
+// fakeV0ControllerServer is a minimal csiv0.ControllerServer used to
+// prove that V0ToV1ControllerServer actually translates requests and
+// responses between spec versions; until this test, the adapter had
+// no callers anywhere in the tree.
+type fakeV0ControllerServer struct {
+	csiv0.ControllerServer
+
+	vols map[string]*csiv0.VolumeInfo
+}
+
+func (f *fakeV0ControllerServer) CreateVolume(
+	ctx context.Context,
+	req *csiv0.CreateVolumeRequest) (*csiv0.CreateVolumeResponse, error) {
+
+	vi := &csiv0.VolumeInfo{
+		Id:         req.Name,
+		Attributes: map[string]string{"name": req.Name},
+	}
+	if cr := req.CapacityRange; cr != nil {
+		vi.CapacityBytes = cr.RequiredBytes
+	}
+	f.vols[vi.Id] = vi
+	return &csiv0.CreateVolumeResponse{VolumeInfo: vi}, nil
+}
+
+func (f *fakeV0ControllerServer) ListVolumes(
+	ctx context.Context,
+	req *csiv0.ListVolumesRequest) (*csiv0.ListVolumesResponse, error) {
+
+	entries := make([]*csiv0.ListVolumesResponse_Entry, 0, len(f.vols))
+	for _, vi := range f.vols {
+		entries = append(entries, &csiv0.ListVolumesResponse_Entry{VolumeInfo: vi})
+	}
+	return &csiv0.ListVolumesResponse{Entries: entries}, nil
+}
+
+func (f *fakeV0ControllerServer) ValidateVolumeCapabilities(
+	ctx context.Context,
+	req *csiv0.ValidateVolumeCapabilitiesRequest,
+) (*csiv0.ValidateVolumeCapabilitiesResponse, error) {
+
+	_, ok := f.vols[req.VolumeId]
+	return &csiv0.ValidateVolumeCapabilitiesResponse{
+		Supported: ok,
+		Message:   "",
+	}, nil
+}
+
+func (f *fakeV0ControllerServer) ControllerGetCapabilities(
+	ctx context.Context,
+	req *csiv0.ControllerGetCapabilitiesRequest,
+) (*csiv0.ControllerGetCapabilitiesResponse, error) {
+
+	return &csiv0.ControllerGetCapabilitiesResponse{
+		Capabilities: []*csiv0.ControllerServiceCapability{
+			{
+				Type: &csiv0.ControllerServiceCapability_Rpc{
+					Rpc: &csiv0.ControllerServiceCapability_RPC{
+						Type: csiv0.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestV0ToV1ControllerServerCreateVolume(t *testing.T) {
+	v0 := &fakeV0ControllerServer{vols: map[string]*csiv0.VolumeInfo{}}
+	v1 := V0ToV1ControllerServer(v0)
+
+	rep, err := v1.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name: "test-volume",
+		CapacityRange: &csi.CapacityRange{
+			RequiredBytes: 1.074e+10,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateVolume: %v", err)
+	}
+	if got, want := rep.Volume.VolumeId, "test-volume"; got != want {
+		t.Errorf("VolumeId = %q, want %q", got, want)
+	}
+	if got, want := rep.Volume.CapacityBytes, int64(1.074e+10); got != want {
+		t.Errorf("CapacityBytes = %d, want %d", got, want)
+	}
+}
+
+func TestV0ToV1ControllerServerListVolumes(t *testing.T) {
+	v0 := &fakeV0ControllerServer{
+		vols: map[string]*csiv0.VolumeInfo{
+			"vol-1": {Id: "vol-1", CapacityBytes: 1.074e+10},
+		},
+	}
+	v1 := V0ToV1ControllerServer(v0)
+
+	rep, err := v1.ListVolumes(context.Background(), &csi.ListVolumesRequest{})
+	if err != nil {
+		t.Fatalf("ListVolumes: %v", err)
+	}
+	if got, want := len(rep.Entries), 1; got != want {
+		t.Fatalf("len(Entries) = %d, want %d", got, want)
+	}
+	if got, want := rep.Entries[0].Volume.VolumeId, "vol-1"; got != want {
+		t.Errorf("VolumeId = %q, want %q", got, want)
+	}
+}
+
+func TestV0ToV1ControllerServerGetCapacityUnimplemented(t *testing.T) {
+	v1 := V0ToV1ControllerServer(&fakeV0ControllerServer{
+		vols: map[string]*csiv0.VolumeInfo{},
+	})
+
+	if _, err := v1.GetCapacity(
+		context.Background(), &csi.GetCapacityRequest{}); err == nil {
+		t.Fatal("GetCapacity: expected error, got nil")
+	}
+}
+
+func TestV0ToV1ControllerServerValidateVolumeCapabilitiesConfirmed(t *testing.T) {
+	v0 := &fakeV0ControllerServer{
+		vols: map[string]*csiv0.VolumeInfo{"vol-1": {Id: "vol-1"}},
+	}
+	v1 := V0ToV1ControllerServer(v0)
+
+	rep, err := v1.ValidateVolumeCapabilities(
+		context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+			VolumeId: "vol-1",
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{AccessMode: &csi.VolumeCapability_AccessMode{
+					Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+				}},
+			},
+		})
+	if err != nil {
+		t.Fatalf("ValidateVolumeCapabilities: %v", err)
+	}
+	if rep.Confirmed == nil {
+		t.Fatal("Confirmed = nil, want non-nil for a supported v0 plugin")
+	}
+	if got, want := len(rep.Confirmed.VolumeCapabilities), 1; got != want {
+		t.Errorf("len(Confirmed.VolumeCapabilities) = %d, want %d", got, want)
+	}
+}
+
+func TestV0ToV1ControllerServerValidateVolumeCapabilitiesUnsupported(t *testing.T) {
+	v0 := &fakeV0ControllerServer{vols: map[string]*csiv0.VolumeInfo{}}
+	v1 := V0ToV1ControllerServer(v0)
+
+	rep, err := v1.ValidateVolumeCapabilities(
+		context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+			VolumeId: "missing",
+		})
+	if err != nil {
+		t.Fatalf("ValidateVolumeCapabilities: %v", err)
+	}
+	if rep.Confirmed != nil {
+		t.Fatal("Confirmed = non-nil, want nil for an unsupported v0 plugin")
+	}
+}
+
+func TestV0ToV1ControllerServerControllerGetCapabilities(t *testing.T) {
+	v0 := &fakeV0ControllerServer{vols: map[string]*csiv0.VolumeInfo{}}
+	v1 := V0ToV1ControllerServer(v0)
+
+	rep, err := v1.ControllerGetCapabilities(
+		context.Background(), &csi.ControllerGetCapabilitiesRequest{})
+	if err != nil {
+		t.Fatalf("ControllerGetCapabilities: %v", err)
+	}
+	if got, want := len(rep.Capabilities), 1; got != want {
+		t.Fatalf("len(Capabilities) = %d, want %d", got, want)
+	}
+	if got, want := rep.Capabilities[0].GetRpc().Type,
+		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME; got != want {
+		t.Errorf("Capabilities[0].Rpc.Type = %v, want %v", got, want)
+	}
+}
+
+// fakeV0ControllerClient is a minimal csiv0.ControllerClient used to
+// prove that V1ToV0ControllerClient's adapter methods, which previously
+// had no caller anywhere in the tree, actually translate requests and
+// responses between spec versions.
+type fakeV0ControllerClient struct {
+	csiv0.ControllerClient
+
+	vols map[string]*csiv0.VolumeInfo
+}
+
+func (f *fakeV0ControllerClient) CreateVolume(
+	ctx context.Context,
+	req *csiv0.CreateVolumeRequest,
+	opts ...grpc.CallOption) (*csiv0.CreateVolumeResponse, error) {
+
+	vi := &csiv0.VolumeInfo{Id: req.Name}
+	if cr := req.CapacityRange; cr != nil {
+		vi.CapacityBytes = cr.RequiredBytes
+	}
+	f.vols[vi.Id] = vi
+	return &csiv0.CreateVolumeResponse{VolumeInfo: vi}, nil
+}
+
+func (f *fakeV0ControllerClient) ValidateVolumeCapabilities(
+	ctx context.Context,
+	req *csiv0.ValidateVolumeCapabilitiesRequest,
+	opts ...grpc.CallOption) (*csiv0.ValidateVolumeCapabilitiesResponse, error) {
+
+	_, ok := f.vols[req.VolumeId]
+	return &csiv0.ValidateVolumeCapabilitiesResponse{Supported: ok}, nil
+}
+
+func (f *fakeV0ControllerClient) ControllerGetCapabilities(
+	ctx context.Context,
+	req *csiv0.ControllerGetCapabilitiesRequest,
+	opts ...grpc.CallOption) (*csiv0.ControllerGetCapabilitiesResponse, error) {
+
+	return &csiv0.ControllerGetCapabilitiesResponse{
+		Capabilities: []*csiv0.ControllerServiceCapability{
+			{
+				Type: &csiv0.ControllerServiceCapability_Rpc{
+					Rpc: &csiv0.ControllerServiceCapability_RPC{
+						Type: csiv0.ControllerServiceCapability_RPC_LIST_VOLUMES,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestV1ToV0ControllerClientCreateVolume(t *testing.T) {
+	v0 := &fakeV0ControllerClient{vols: map[string]*csiv0.VolumeInfo{}}
+	v1 := V1ToV0ControllerClient(v0)
+
+	rep, err := v1.CreateVolume(context.Background(), &csi.CreateVolumeRequest{
+		Name: "test-volume",
+		CapacityRange: &csi.CapacityRange{
+			RequiredBytes: 1.074e+10,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateVolume: %v", err)
+	}
+	if got, want := rep.Volume.VolumeId, "test-volume"; got != want {
+		t.Errorf("VolumeId = %q, want %q", got, want)
+	}
+	if got, want := rep.Volume.CapacityBytes, int64(1.074e+10); got != want {
+		t.Errorf("CapacityBytes = %d, want %d", got, want)
+	}
+}
+
+func TestV1ToV0ControllerClientValidateVolumeCapabilitiesConfirmed(t *testing.T) {
+	v0 := &fakeV0ControllerClient{
+		vols: map[string]*csiv0.VolumeInfo{"vol-1": {Id: "vol-1"}},
+	}
+	v1 := V1ToV0ControllerClient(v0)
+
+	rep, err := v1.ValidateVolumeCapabilities(
+		context.Background(), &csi.ValidateVolumeCapabilitiesRequest{
+			VolumeId: "vol-1",
+		})
+	if err != nil {
+		t.Fatalf("ValidateVolumeCapabilities: %v", err)
+	}
+	if rep.Confirmed == nil {
+		t.Fatal("Confirmed = nil, want non-nil for a supported v0 plugin")
+	}
+}
+
+func TestV1ToV0ControllerClientControllerGetCapabilities(t *testing.T) {
+	v0 := &fakeV0ControllerClient{vols: map[string]*csiv0.VolumeInfo{}}
+	v1 := V1ToV0ControllerClient(v0)
+
+	rep, err := v1.ControllerGetCapabilities(
+		context.Background(), &csi.ControllerGetCapabilitiesRequest{})
+	if err != nil {
+		t.Fatalf("ControllerGetCapabilities: %v", err)
+	}
+	if got, want := len(rep.Capabilities), 1; got != want {
+		t.Fatalf("len(Capabilities) = %d, want %d", got, want)
+	}
+	if got, want := rep.Capabilities[0].GetRpc().Type,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES; got != want {
+		t.Errorf("Capabilities[0].Rpc.Type = %v, want %v", got, want)
+	}
+}
+
+// fakeIdentityServer backs the bufconn server used to exercise
+// ProbeAndNegotiate end-to-end; GetPluginCapabilities returns capsErr
+// verbatim so tests can simulate both a v0.x plugin (Unimplemented) and
+// an unrelated RPC failure.
+type fakeIdentityServer struct {
+	csi.IdentityServer
+
+	capsErr error
+}
+
+func (f *fakeIdentityServer) GetPluginInfo(
+	ctx context.Context,
+	req *csi.GetPluginInfoRequest) (*csi.GetPluginInfoResponse, error) {
+
+	return &csi.GetPluginInfoResponse{Name: "fake", VendorVersion: "0.0.0"}, nil
+}
+
+func (f *fakeIdentityServer) GetPluginCapabilities(
+	ctx context.Context,
+	req *csi.GetPluginCapabilitiesRequest,
+) (*csi.GetPluginCapabilitiesResponse, error) {
+
+	if f.capsErr != nil {
+		return nil, f.capsErr
+	}
+	return &csi.GetPluginCapabilitiesResponse{}, nil
+}
+
+func (f *fakeIdentityServer) Probe(
+	ctx context.Context, req *csi.ProbeRequest) (*csi.ProbeResponse, error) {
+
+	return &csi.ProbeResponse{}, nil
+}
+
+// startFakeIdentityServer starts id behind an in-process bufconn
+// listener and returns a ClientConn dialed to it and a function that
+// stops the server.
+func startFakeIdentityServer(
+	t *testing.T, id csi.IdentityServer) (*grpc.ClientConn, func()) {
+
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	svr := grpc.NewServer()
+	csi.RegisterIdentityServer(svr, id)
+	go svr.Serve(lis)
+
+	cc, err := grpc.DialContext(
+		context.Background(), "bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure())
+	if err != nil {
+		svr.Stop()
+		t.Fatalf("dial: %v", err)
+	}
+
+	return cc, func() {
+		cc.Close()
+		svr.Stop()
+	}
+}
+
+func TestProbeAndNegotiateV1Plugin(t *testing.T) {
+	cc, stop := startFakeIdentityServer(t, &fakeIdentityServer{})
+	defer stop()
+
+	client, err := ProbeAndNegotiate(context.Background(), cc)
+	if err != nil {
+		t.Fatalf("ProbeAndNegotiate: %v", err)
+	}
+	if _, ok := client.(*v1ToV0ControllerClient); ok {
+		t.Fatal("got the v0 adapter, want the v1.x client as-is")
+	}
+}
+
+func TestProbeAndNegotiateV0Plugin(t *testing.T) {
+	cc, stop := startFakeIdentityServer(t, &fakeIdentityServer{
+		capsErr: status.Error(codes.Unimplemented, "GetPluginCapabilities"),
+	})
+	defer stop()
+
+	client, err := ProbeAndNegotiate(context.Background(), cc)
+	if err != nil {
+		t.Fatalf("ProbeAndNegotiate: %v", err)
+	}
+	if _, ok := client.(*v1ToV0ControllerClient); !ok {
+		t.Fatal("got the v1.x client, want the v0 adapter")
+	}
+}
+
+func TestProbeAndNegotiatePropagatesOtherErrors(t *testing.T) {
+	wantErr := status.Error(codes.Unavailable, "connection reset")
+	cc, stop := startFakeIdentityServer(t, &fakeIdentityServer{
+		capsErr: wantErr,
+	})
+	defer stop()
+
+	if _, err := ProbeAndNegotiate(context.Background(), cc); status.Code(err) != codes.Unavailable {
+		t.Fatalf("ProbeAndNegotiate err = %v, want %v", err, wantErr)
+	}
+}
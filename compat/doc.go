@@ -0,0 +1,28 @@
+// Package compat re-exports the CSI v1.x spec package
+// (github.com/container-storage-interface/spec/lib/go/csi) under a
+// name that does not collide with gocsi's existing, CSI 0.x-based
+// "github.com/thecodeteam/gocsi/csi" import, and hosts the adapters
+// that let v0 plugins and v1 callers interoperate. See compat.go for
+// the translation layer and negotiation helper.
+package compat
+
+import (
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+// Alias the generated client/server interfaces so callers can depend
+// on compat without importing the upstream spec package directly.
+type (
+	// ControllerClient is the CSI v1.x controller client interface.
+	ControllerClient = csi.ControllerClient
+	// ControllerServer is the CSI v1.x controller server interface.
+	ControllerServer = csi.ControllerServer
+	// IdentityClient is the CSI v1.x identity client interface.
+	IdentityClient = csi.IdentityClient
+	// IdentityServer is the CSI v1.x identity server interface.
+	IdentityServer = csi.IdentityServer
+	// NodeClient is the CSI v1.x node client interface.
+	NodeClient = csi.NodeClient
+	// NodeServer is the CSI v1.x node server interface.
+	NodeServer = csi.NodeServer
+)
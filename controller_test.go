@@ -9,10 +9,12 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/thecodeteam/gocsi"
-	"github.com/thecodeteam/gocsi/csi"
 	"github.com/thecodeteam/gocsi/mock/service"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
 )
 
 var _ = Describe("Controller", func() {
@@ -23,19 +25,17 @@ var _ = Describe("Controller", func() {
 		gclient  *grpc.ClientConn
 		client   csi.ControllerClient
 
-		version *csi.Version
-
-		vol       *csi.VolumeInfo
-		volID     string
-		volName   string
-		reqBytes  uint64
-		limBytes  uint64
-		fsType    string
-		mntFlags  []string
-		params    map[string]string
-		userCreds map[string]string
-
-		pubVolInfo map[string]string
+		vol      *csi.Volume
+		volID    string
+		volName  string
+		reqBytes int64
+		limBytes int64
+		fsType   string
+		mntFlags []string
+		params   map[string]string
+		secrets  map[string]string
+
+		pubContext map[string]string
 	)
 	BeforeEach(func() {
 		ctx = context.Background()
@@ -43,8 +43,6 @@ var _ = Describe("Controller", func() {
 		Ω(err).ShouldNot(HaveOccurred())
 		client = csi.NewControllerClient(gclient)
 
-		version = &mockSupportedVersions[0]
-
 		volID = "4"
 		volName = "Test Volume"
 		reqBytes = 1.074e+10 //  10GiB
@@ -52,7 +50,7 @@ var _ = Describe("Controller", func() {
 		fsType = "ext4"
 		mntFlags = []string{"-o noexec"}
 		params = map[string]string{"tag": "gold"}
-		userCreds = map[string]string{"beour": "guest"}
+		secrets = map[string]string{"beour": "guest"}
 	})
 	AfterEach(func() {
 		ctx = nil
@@ -61,8 +59,6 @@ var _ = Describe("Controller", func() {
 		client = nil
 		stopMock()
 
-		version = nil
-
 		vol = nil
 		volID = ""
 		volName = ""
@@ -71,14 +67,11 @@ var _ = Describe("Controller", func() {
 		fsType = ""
 		mntFlags = nil
 		params = nil
-		pubVolInfo = nil
+		pubContext = nil
 	})
 
-	listVolumes := func() (vols []csi.VolumeInfo, err error) {
-		cvol, cerr := gocsi.PageAllVolumes(
-			ctx,
-			client,
-			csi.ListVolumesRequest{Version: version})
+	listVolumes := func() (vols []csi.ListVolumesResponse_Entry, err error) {
+		cvol, cerr := gocsi.PageAllVolumes(ctx, client)
 		for {
 			select {
 			case v, ok := <-cvol:
@@ -95,10 +88,9 @@ var _ = Describe("Controller", func() {
 		}
 	}
 
-	createNewVolumeWithResult := func() (*csi.VolumeInfo, error) {
+	createNewVolumeWithResult := func() (*csi.Volume, error) {
 		req := &csi.CreateVolumeRequest{
-			Name:    volName,
-			Version: version,
+			Name: volName,
 			CapacityRange: &csi.CapacityRange{
 				RequiredBytes: reqBytes,
 				LimitBytes:    limBytes,
@@ -106,14 +98,14 @@ var _ = Describe("Controller", func() {
 			VolumeCapabilities: []*csi.VolumeCapability{
 				gocsi.NewMountCapability(0, fsType, mntFlags...),
 			},
-			UserCredentials: userCreds,
-			Parameters:      params,
+			Secrets:    secrets,
+			Parameters: params,
 		}
 		res, err := client.CreateVolume(ctx, req)
 		if res == nil {
 			return nil, err
 		}
-		return res.VolumeInfo, err
+		return res.Volume, err
 	}
 
 	createNewVolume := func() {
@@ -121,7 +113,7 @@ var _ = Describe("Controller", func() {
 	}
 
 	validateNewVolumeResult := func(
-		vol *csi.VolumeInfo,
+		vol *csi.Volume,
 		err error) bool {
 
 		if err != nil {
@@ -131,8 +123,8 @@ var _ = Describe("Controller", func() {
 
 		Ω(vol).ShouldNot(BeNil())
 		Ω(vol.CapacityBytes).Should(Equal(limBytes))
-		Ω(vol.Id).Should(Equal(volID))
-		Ω(vol.Attributes["name"]).Should(Equal(volName))
+		Ω(vol.VolumeId).Should(Equal(volID))
+		Ω(vol.VolumeContext["name"]).Should(Equal(volName))
 		return false
 	}
 
@@ -155,7 +147,7 @@ var _ = Describe("Controller", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(vol).ShouldNot(BeNil())
 				Ω(vol.CapacityBytes).Should(Equal(reqBytes))
-				Ω(vol.Attributes["name"]).Should(Equal(volName))
+				Ω(vol.VolumeContext["name"]).Should(Equal(volName))
 			})
 		})
 		Context("Missing Name", func() {
@@ -271,6 +263,115 @@ var _ = Describe("Controller", func() {
 				It("Should Be Valid", validateIdempResult)
 			})
 		})
+		Context("Clone Volume", func() {
+			var (
+				srcVol    *csi.Volume
+				cloneName string
+				cloneVol  *csi.Volume
+				cloneErr  error
+			)
+			BeforeEach(func() {
+				cloneName = "Cloned Volume"
+			})
+			AfterEach(func() {
+				srcVol = nil
+				cloneName = ""
+				cloneVol = nil
+				cloneErr = nil
+			})
+			cloneFrom := func(sourceVolumeID string) (*csi.Volume, error) {
+				res, err := client.CreateVolume(
+					ctx,
+					&csi.CreateVolumeRequest{
+						Name: cloneName,
+						CapacityRange: &csi.CapacityRange{
+							RequiredBytes: srcVol.CapacityBytes,
+						},
+						VolumeCapabilities: []*csi.VolumeCapability{
+							gocsi.NewMountCapability(0, fsType, mntFlags...),
+						},
+						VolumeContentSource: gocsi.NewVolumeSourceVolumeContentSource(
+							sourceVolumeID),
+					})
+				if res == nil {
+					return nil, err
+				}
+				return res.Volume, err
+			}
+			JustBeforeEach(func() {
+				srcVol = vol
+				cloneVol, cloneErr = cloneFrom(srcVol.VolumeId)
+			})
+			It("Should Clone The Volume", func() {
+				Ω(cloneErr).ShouldNot(HaveOccurred())
+				Ω(cloneVol).ShouldNot(BeNil())
+				Ω(cloneVol.VolumeId).ShouldNot(Equal(srcVol.VolumeId))
+				Ω(cloneVol.CapacityBytes).Should(Equal(srcVol.CapacityBytes))
+				Ω(cloneVol.VolumeContext["name"]).Should(Equal(cloneName))
+			})
+			Context("Repeated With The Same Source", func() {
+				It("Should Be Idempotent", func() {
+					Ω(cloneErr).ShouldNot(HaveOccurred())
+					againVol, againErr := cloneFrom(srcVol.VolumeId)
+					Ω(againErr).ShouldNot(HaveOccurred())
+					Ω(againVol.VolumeId).Should(Equal(cloneVol.VolumeId))
+				})
+			})
+			Context("Repeated With A Different Source", func() {
+				It("Should Be AlreadyExists", func() {
+					Ω(cloneErr).ShouldNot(HaveOccurred())
+					volName = "Other Volume"
+					otherVol, err := createNewVolumeWithResult()
+					Ω(err).ShouldNot(HaveOccurred())
+					_, err = cloneFrom(otherVol.VolumeId)
+					Ω(err).Should(HaveOccurred())
+					Ω(status.Code(err)).Should(Equal(codes.AlreadyExists))
+				})
+			})
+		})
+		Context("Restore From Snapshot", func() {
+			var (
+				srcSnap    *csi.Snapshot
+				restoreVol *csi.Volume
+				restoreErr error
+			)
+			AfterEach(func() {
+				srcSnap = nil
+				restoreVol = nil
+				restoreErr = nil
+			})
+			JustBeforeEach(func() {
+				res, err := client.CreateSnapshot(
+					ctx, &csi.CreateSnapshotRequest{
+						Name:           "restore-snap",
+						SourceVolumeId: "1",
+					})
+				Ω(err).ShouldNot(HaveOccurred())
+				srcSnap = res.Snapshot
+
+				res2, err2 := client.CreateVolume(
+					ctx,
+					&csi.CreateVolumeRequest{
+						Name: "Restored Volume",
+						VolumeCapabilities: []*csi.VolumeCapability{
+							gocsi.NewMountCapability(0, fsType, mntFlags...),
+						},
+						VolumeContentSource: gocsi.NewSnapshotSourceVolumeContentSource(
+							srcSnap.SnapshotId),
+					})
+				if res2 == nil {
+					restoreVol, restoreErr = nil, err2
+					return
+				}
+				restoreVol, restoreErr = res2.Volume, err2
+			})
+			It("Should Restore The Volume", func() {
+				Ω(restoreErr).ShouldNot(HaveOccurred())
+				Ω(restoreVol).ShouldNot(BeNil())
+				Ω(restoreVol.VolumeContext["name"]).Should(Equal("Restored Volume"))
+				Ω(restoreVol.ContentSource).ShouldNot(BeNil())
+			})
+		})
 	})
 
 	Describe("DeleteVolume", func() {
@@ -285,7 +386,6 @@ var _ = Describe("Controller", func() {
 			_, err = client.DeleteVolume(
 				ctx,
 				&csi.DeleteVolumeRequest{
-					Version:  version,
 					VolumeId: volID,
 				})
 		})
@@ -313,21 +413,172 @@ var _ = Describe("Controller", func() {
 				Ω(err).Should(Σ(gocsi.ErrVolumeIDRequired))
 			})
 		})
-		Context("Missing Version", func() {
+	})
+
+	Describe("CreateSnapshot", func() {
+		var (
+			snap    *csi.Snapshot
+			snapErr error
+		)
+		createSnapshot := func(name, srcVolID string) (*csi.Snapshot, error) {
+			res, err := client.CreateSnapshot(ctx, &csi.CreateSnapshotRequest{
+				Name:           name,
+				SourceVolumeId: srcVolID,
+			})
+			if res == nil {
+				return nil, err
+			}
+			return res.Snapshot, err
+		}
+		AfterEach(func() {
+			snap = nil
+			snapErr = nil
+		})
+		Context("Normal Create Snapshot Call", func() {
+			BeforeEach(func() {
+				snap, snapErr = createSnapshot("snap1", "1")
+			})
+			It("Should Be Valid", func() {
+				Ω(snapErr).ShouldNot(HaveOccurred())
+				Ω(snap).ShouldNot(BeNil())
+				Ω(snap.SourceVolumeId).Should(Equal("1"))
+			})
+		})
+		Context("Missing Source Volume ID", func() {
 			BeforeEach(func() {
-				version = nil
+				snap, snapErr = createSnapshot("snap2", "")
 			})
 			It("Should Not Be Valid", func() {
-				Ω(err).Should(HaveOccurred())
-				Ω(err).Should(ΣCM(
-					codes.InvalidArgument,
-					"invalid request version: nil"))
+				Ω(snapErr).Should(HaveOccurred())
+				Ω(snap).Should(BeNil())
+				Ω(snapErr).Should(Σ(gocsi.ErrSourceVolumeIDRequired))
+			})
+		})
+		Context("Idempotent Create", func() {
+			It("Should Return The Same Snapshot", func() {
+				snap1, err1 := createSnapshot("snap3", "2")
+				Ω(err1).ShouldNot(HaveOccurred())
+				snap2, err2 := createSnapshot("snap3", "2")
+				Ω(err2).ShouldNot(HaveOccurred())
+				Ω(snap2.SnapshotId).Should(Equal(snap1.SnapshotId))
+			})
+		})
+	})
+
+	Describe("DeleteSnapshot", func() {
+		var (
+			snapID string
+			delErr error
+		)
+		JustBeforeEach(func() {
+			_, delErr = client.DeleteSnapshot(
+				ctx, &csi.DeleteSnapshotRequest{SnapshotId: snapID})
+		})
+		AfterEach(func() {
+			snapID = ""
+			delErr = nil
+		})
+		Context("Missing Snapshot ID", func() {
+			BeforeEach(func() {
+				snapID = ""
+			})
+			It("Should Not Be Valid", func() {
+				Ω(delErr).Should(HaveOccurred())
+				Ω(delErr).Should(Σ(gocsi.ErrSnapshotIDRequired))
+			})
+		})
+	})
+
+	Describe("ControllerExpandVolume", func() {
+		var (
+			expVolID   string
+			expRange   *csi.CapacityRange
+			capBytes   int64
+			expandErr  error
+			nodeExpand bool
+		)
+		BeforeEach(func() {
+			expVolID = "1"
+		})
+		AfterEach(func() {
+			expRange = nil
+			capBytes = 0
+			expandErr = nil
+			nodeExpand = false
+		})
+		JustBeforeEach(func() {
+			capBytes, nodeExpand, expandErr = gocsi.ExpandVolume(
+				ctx, client, expVolID, expRange, secrets)
+		})
+		Context("Expand Past Current Size", func() {
+			BeforeEach(func() {
+				expRange = &csi.CapacityRange{RequiredBytes: 2.148e+10}
+			})
+			It("Should Be Valid", func() {
+				Ω(expandErr).ShouldNot(HaveOccurred())
+				Ω(capBytes).Should(Equal(int64(2.148e+10)))
+				Ω(nodeExpand).Should(BeTrue())
+			})
+		})
+		Context("Shrink Rejected", func() {
+			BeforeEach(func() {
+				expRange = &csi.CapacityRange{RequiredBytes: 1}
+			})
+			It("Should Not Be Valid", func() {
+				Ω(expandErr).Should(HaveOccurred())
+				Ω(expandErr).Should(Σ(gocsi.ErrExpansionNotSupported))
+			})
+		})
+		Context("Expand Past Limit Rejected", func() {
+			BeforeEach(func() {
+				expRange = &csi.CapacityRange{
+					RequiredBytes: 2.148e+10,
+					LimitBytes:    1.5e+10,
+				}
+			})
+			It("Should Not Be Valid", func() {
+				Ω(expandErr).Should(HaveOccurred())
+				Ω(expandErr).Should(Σ(gocsi.ErrExpansionNotSupported))
+			})
+		})
+		Context("Limit Above Required Expands To Required", func() {
+			BeforeEach(func() {
+				expRange = &csi.CapacityRange{
+					RequiredBytes: 2.148e+10,
+					LimitBytes:    1.074e+11,
+				}
+			})
+			It("Should Expand To RequiredBytes, Not LimitBytes", func() {
+				Ω(expandErr).ShouldNot(HaveOccurred())
+				Ω(capBytes).Should(Equal(int64(2.148e+10)))
+				Ω(nodeExpand).Should(BeTrue())
+			})
+		})
+		Context("Missing Capacity Range", func() {
+			BeforeEach(func() {
+				expRange = nil
+			})
+			It("Should Not Be Valid", func() {
+				Ω(expandErr).Should(HaveOccurred())
+				Ω(expandErr).Should(Σ(gocsi.ErrVolumeCapacityRangeRequired))
+			})
+		})
+		Context("Idempotent Expand", func() {
+			BeforeEach(func() {
+				expRange = &csi.CapacityRange{RequiredBytes: 2.148e+10}
+			})
+			It("Should Be Valid Twice", func() {
+				Ω(expandErr).ShouldNot(HaveOccurred())
+				capBytes2, _, err2 := gocsi.ExpandVolume(
+					ctx, client, expVolID, expRange, secrets)
+				Ω(err2).ShouldNot(HaveOccurred())
+				Ω(capBytes2).Should(Equal(capBytes))
 			})
 		})
 	})
 
 	Describe("ListVolumes", func() {
-		var vols []csi.VolumeInfo
+		var vols []csi.ListVolumesResponse_Entry
 		AfterEach(func() {
 			vols = nil
 		})
@@ -360,7 +611,6 @@ var _ = Describe("Controller", func() {
 
 		publishVolume := func() {
 			req := &csi.ControllerPublishVolumeRequest{
-				Version:  version,
 				VolumeId: "1",
 				NodeId:   service.Name,
 				Readonly: true,
@@ -370,13 +620,13 @@ var _ = Describe("Controller", func() {
 			}
 			res, err := client.ControllerPublishVolume(ctx, req)
 			Ω(err).ShouldNot(HaveOccurred())
-			pubVolInfo = res.PublishVolumeInfo
+			pubContext = res.PublishContext
 		}
 
 		shouldBePublished := func() {
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(pubVolInfo).ShouldNot(BeNil())
-			Ω(pubVolInfo["device"]).Should(Equal("/dev/mock"))
+			Ω(pubContext).ShouldNot(BeNil())
+			Ω(pubContext["device"]).Should(Equal("/dev/mock"))
 		}
 
 		BeforeEach(func() {
@@ -388,7 +638,7 @@ var _ = Describe("Controller", func() {
 				vols, err := listVolumes()
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(vols).Should(HaveLen(3))
-				Ω(vols[0].Attributes[devPathKey]).Should(Equal("/dev/mock"))
+				Ω(vols[0].Volume.VolumeContext[devPathKey]).Should(Equal("/dev/mock"))
 			})
 		})
 
@@ -398,7 +648,6 @@ var _ = Describe("Controller", func() {
 				_, err := client.ControllerUnpublishVolume(
 					ctx,
 					&csi.ControllerUnpublishVolumeRequest{
-						Version:  version,
 						VolumeId: "1",
 						NodeId:   service.Name,
 					})
@@ -408,9 +657,52 @@ var _ = Describe("Controller", func() {
 				vols, err := listVolumes()
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(vols).Should(HaveLen(3))
-				_, ok := vols[0].Attributes[devPathKey]
+				_, ok := vols[0].Volume.VolumeContext[devPathKey]
 				Ω(ok).Should(BeFalse())
 			})
 		})
+
+		Context("PublishVolume To Multiple Nodes", func() {
+			BeforeEach(func() {
+				_, err := client.ControllerPublishVolume(
+					ctx,
+					&csi.ControllerPublishVolumeRequest{
+						VolumeId: "1",
+						NodeId:   "other-node",
+						Readonly: true,
+						VolumeCapability: gocsi.NewMountCapability(
+							csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+							"mock"),
+					})
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+			It("Should Round-Trip Through ListVolumes", func() {
+				vols, err := listVolumes()
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(vols[0].Status.PublishedNodeIds).Should(ConsistOf(
+					service.Name, "other-node"))
+			})
+			It("Should Filter By Published Node", func() {
+				cvol, cerr := gocsi.PageAllVolumes(
+					ctx, client, gocsi.WithPublishedNodeFilter("other-node"))
+				var vols []csi.ListVolumesResponse_Entry
+				for {
+					select {
+					case v, ok := <-cvol:
+						if !ok {
+							Ω(vols).Should(HaveLen(1))
+							Ω(vols[0].Volume.VolumeId).Should(Equal("1"))
+							return
+						}
+						vols = append(vols, v)
+					case e, ok := <-cerr:
+						if !ok {
+							return
+						}
+						Ω(e).ShouldNot(HaveOccurred())
+					}
+				}
+			})
+		})
 	})
 })
@@ -0,0 +1,20 @@
+package gocsi
+
+import "golang.org/x/net/context"
+
+type correlationIDContextKey struct{}
+
+// WithCorrelationID stashes id on ctx so a later CorrelationIDFromContext
+// call, such as the one made by the logging interceptor, retrieves it.
+// It is typically called by gocsi/middleware/tracing's interceptors
+// rather than directly by plugin authors.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID previously stored
+// on ctx via WithCorrelationID, if any.
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDContextKey{}).(string)
+	return id, ok
+}
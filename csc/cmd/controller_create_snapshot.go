@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+var createSnapshot struct {
+	sourceVolumeID string
+	secrets        map[string]string
+	params         map[string]string
+}
+
+var createSnapshotCmd = &cobra.Command{
+	Use:   "create-snapshot",
+	Short: `invokes the rpc "CreateSnapshot"`,
+	Example: `
+USAGE
+
+    csc controller create-snapshot [flags] SNAPSHOT_NAME
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(root.ctx, root.timeout)
+		defer cancel()
+
+		req := csi.CreateSnapshotRequest{
+			Name:           args[0],
+			SourceVolumeId: createSnapshot.sourceVolumeID,
+			Secrets:        createSnapshot.secrets,
+			Parameters:     createSnapshot.params,
+		}
+
+		rep, err := controller.client.CreateSnapshot(ctx, &req)
+		if err != nil {
+			return err
+		}
+		return root.tpl.Execute(os.Stdout, rep)
+	},
+}
+
+func init() {
+	controllerCmd.AddCommand(createSnapshotCmd)
+
+	createSnapshotCmd.Flags().StringVar(
+		&createSnapshot.sourceVolumeID,
+		"source-volume-id",
+		"",
+		"the ID of the volume to snapshot")
+
+	createSnapshotCmd.Flags().StringToStringVar(
+		&createSnapshot.secrets,
+		"secrets",
+		nil,
+		"one or more key/value pairs sent as the request's Secrets field")
+
+	createSnapshotCmd.Flags().StringToStringVar(
+		&createSnapshot.params,
+		"params",
+		nil,
+		"one or more key/value pairs sent as the request's Parameters field")
+
+	createSnapshotCmd.Flags().StringVar(
+		&root.format,
+		"format",
+		"",
+		"the go template format used to emit the results")
+}
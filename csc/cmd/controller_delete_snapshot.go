@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+var deleteSnapshot struct {
+	secrets map[string]string
+}
+
+var deleteSnapshotCmd = &cobra.Command{
+	Use:   "delete-snapshot",
+	Short: `invokes the rpc "DeleteSnapshot"`,
+	Example: `
+USAGE
+
+    csc controller delete-snapshot [flags] SNAPSHOT_ID [SNAPSHOT_ID...]
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		req := csi.DeleteSnapshotRequest{
+			Secrets: deleteSnapshot.secrets,
+		}
+
+		for i := range args {
+			ctx, cancel := context.WithTimeout(root.ctx, root.timeout)
+			defer cancel()
+
+			req.SnapshotId = args[i]
+
+			log.WithField("request", req).Debug("deleting snapshot")
+			_, err := controller.client.DeleteSnapshot(ctx, &req)
+			if err != nil {
+				return err
+			}
+			fmt.Println(args[i])
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	controllerCmd.AddCommand(deleteSnapshotCmd)
+
+	deleteSnapshotCmd.Flags().StringToStringVar(
+		&deleteSnapshot.secrets,
+		"secrets",
+		nil,
+		"one or more key/value pairs sent as the request's Secrets field")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+var controllerExpandVolume struct {
+	requiredBytes int64
+	limitBytes    int64
+	secrets       map[string]string
+}
+
+var controllerExpandVolumeCmd = &cobra.Command{
+	Use:   "expand-volume",
+	Short: `invokes the rpc "ControllerExpandVolume"`,
+	Example: `
+USAGE
+
+    csc controller expand-volume [flags] VOLUME_ID [VOLUME_ID...]
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		req := csi.ControllerExpandVolumeRequest{
+			CapacityRange: &csi.CapacityRange{
+				RequiredBytes: controllerExpandVolume.requiredBytes,
+				LimitBytes:    controllerExpandVolume.limitBytes,
+			},
+			Secrets: controllerExpandVolume.secrets,
+		}
+
+		for i := range args {
+			ctx, cancel := context.WithTimeout(root.ctx, root.timeout)
+			defer cancel()
+
+			req.VolumeId = args[i]
+
+			log.WithField("request", req).Debug("expanding volume")
+			rep, err := controller.client.ControllerExpandVolume(ctx, &req)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s\t%d\t%v\n",
+				args[i], rep.CapacityBytes, rep.NodeExpansionRequired)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	controllerCmd.AddCommand(controllerExpandVolumeCmd)
+
+	controllerExpandVolumeCmd.Flags().Int64Var(
+		&controllerExpandVolume.requiredBytes,
+		"required-bytes",
+		0,
+		"the minimum number of bytes the volume must be expanded to")
+
+	controllerExpandVolumeCmd.Flags().Int64Var(
+		&controllerExpandVolume.limitBytes,
+		"limit-bytes",
+		0,
+		"the maximum number of bytes the volume may be expanded to")
+
+	controllerExpandVolumeCmd.Flags().StringToStringVar(
+		&controllerExpandVolume.secrets,
+		"secrets",
+		nil,
+		"one or more key/value pairs sent as the request's Secrets field")
+}
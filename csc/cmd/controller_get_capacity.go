@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+var getCapacityCmd = &cobra.Command{
+	Use:   "get-capacity",
+	Short: `invokes the rpc "GetCapacity"`,
+	RunE: func(*cobra.Command, []string) error {
+		ctx, cancel := context.WithTimeout(root.ctx, root.timeout)
+		defer cancel()
+
+		rep, err := controller.client.GetCapacity(
+			ctx, &csi.GetCapacityRequest{})
+		if err != nil {
+			return err
+		}
+		return root.tpl.Execute(os.Stdout, rep)
+	},
+}
+
+func init() {
+	controllerCmd.AddCommand(getCapacityCmd)
+
+	getCapacityCmd.Flags().StringVar(
+		&root.format,
+		"format",
+		"",
+		"the go template format used to emit the results")
+}
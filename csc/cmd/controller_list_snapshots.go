@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/thecodeteam/gocsi"
+)
+
+var listSnapshots struct {
+	sourceVolumeID string
+	snapshotID     string
+	maxEntries     uint32
+	startingToken  string
+	paging         bool
+}
+
+var listSnapshotsCmd = &cobra.Command{
+	Use:     "list-snapshots",
+	Aliases: []string{"snapshots"},
+	Short:   `invokes the rpc "ListSnapshots"`,
+	RunE: func(*cobra.Command, []string) error {
+		ctx, cancel := context.WithTimeout(root.ctx, root.timeout)
+		defer cancel()
+
+		req := csi.ListSnapshotsRequest{
+			MaxEntries:     listSnapshots.maxEntries,
+			StartingToken:  listSnapshots.startingToken,
+			SourceVolumeId: listSnapshots.sourceVolumeID,
+			SnapshotId:     listSnapshots.snapshotID,
+		}
+
+		// If auto-paging is not enabled then send a normal request.
+		if !listSnapshots.paging {
+			rep, err := controller.client.ListSnapshots(ctx, &req)
+			if err != nil {
+				return err
+			}
+			return root.tpl.Execute(os.Stdout, rep)
+		}
+
+		// Paging is enabled.
+		csnap, cerr := gocsi.PageAllSnapshots(ctx, controller.client, req)
+		for {
+			select {
+			case v, ok := <-csnap:
+				if !ok {
+					return nil
+				}
+				if err := root.tpl.Execute(os.Stdout, v); err != nil {
+					return err
+				}
+			case e, ok := <-cerr:
+				if !ok {
+					return nil
+				}
+				return e
+			}
+		}
+	},
+}
+
+func init() {
+	controllerCmd.AddCommand(listSnapshotsCmd)
+
+	listSnapshotsCmd.Flags().StringVar(
+		&listSnapshots.sourceVolumeID,
+		"source-volume-id",
+		"",
+		"filter results to snapshots of the specified source volume")
+
+	listSnapshotsCmd.Flags().StringVar(
+		&listSnapshots.snapshotID,
+		"snapshot-id",
+		"",
+		"filter results to the specified snapshot")
+
+	listSnapshotsCmd.Flags().Uint32Var(
+		&listSnapshots.maxEntries,
+		"max-entries",
+		0,
+		"the maximum number of entries to return")
+
+	listSnapshotsCmd.Flags().StringVar(
+		&listSnapshots.startingToken,
+		"starting-token",
+		"",
+		"the starting token used to retrieve paged data")
+
+	listSnapshotsCmd.Flags().BoolVar(
+		&listSnapshots.paging,
+		"paging",
+		false,
+		"a flag that enables auto-paging")
+
+	listSnapshotsCmd.Flags().StringVar(
+		&root.format,
+		"format",
+		"",
+		"the go template format used to emit the results")
+}
@@ -6,8 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/thecodeteam/gocsi"
-	"github.com/thecodeteam/gocsi/csi"
 )
 
 var listVolumes struct {
@@ -25,7 +25,6 @@ var listVolumesCmd = &cobra.Command{
 		defer cancel()
 
 		req := csi.ListVolumesRequest{
-			Version:       &root.version.Version,
 			MaxEntries:    listVolumes.maxEntries,
 			StartingToken: listVolumes.startingToken,
 		}
@@ -40,14 +39,18 @@ var listVolumesCmd = &cobra.Command{
 		}
 
 		// Paging is enabled.
-		cvol, cerr := gocsi.PageAllVolumes(ctx, controller.client, req)
+		cvol, cerr := gocsi.PageAllVolumes(
+			ctx,
+			controller.client,
+			gocsi.WithMaxEntries(listVolumes.maxEntries),
+			gocsi.WithStartingToken(listVolumes.startingToken))
 		for {
 			select {
-			case v, ok := <-cvol:
+			case e, ok := <-cvol:
 				if !ok {
 					return nil
 				}
-				if err := root.tpl.Execute(os.Stdout, v); err != nil {
+				if err := root.tpl.Execute(os.Stdout, e); err != nil {
 					return err
 				}
 			case e, ok := <-cerr:
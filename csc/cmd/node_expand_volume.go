@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+var nodeExpandVolume struct {
+	volumePath string
+	capacity   int64
+}
+
+var nodeExpandVolumeCmd = &cobra.Command{
+	Use:   "expand-volume",
+	Short: `invokes the rpc "NodeExpandVolume"`,
+	Example: `
+USAGE
+
+    csc node expand-volume [flags] VOLUME_ID [VOLUME_ID...]
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		req := csi.NodeExpandVolumeRequest{
+			VolumePath: nodeExpandVolume.volumePath,
+			CapacityRange: &csi.CapacityRange{
+				RequiredBytes: nodeExpandVolume.capacity,
+			},
+		}
+
+		for i := range args {
+			ctx, cancel := context.WithTimeout(root.ctx, root.timeout)
+			defer cancel()
+
+			req.VolumeId = args[i]
+
+			log.WithField("request", req).Debug("expanding volume")
+			_, err := node.client.NodeExpandVolume(ctx, &req)
+			if err != nil {
+				return err
+			}
+			fmt.Println(args[i])
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	nodeCmd.AddCommand(nodeExpandVolumeCmd)
+
+	nodeExpandVolumeCmd.Flags().StringVar(
+		&nodeExpandVolume.volumePath,
+		"volume-path",
+		"",
+		"the path at which the volume is published on the node")
+
+	nodeExpandVolumeCmd.Flags().Int64Var(
+		&nodeExpandVolume.capacity,
+		"required-bytes",
+		0,
+		"the minimum number of bytes the volume must be expanded to")
+}
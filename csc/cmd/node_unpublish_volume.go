@@ -7,7 +7,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
-	"github.com/thecodeteam/gocsi/csi"
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
 )
 
 var nodeUnpublishVolume struct {
@@ -28,9 +28,8 @@ USAGE
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		req := csi.NodeUnpublishVolumeRequest{
-			Version:         &root.version.Version,
-			TargetPath:      nodeUnpublishVolume.targetPath,
-			UserCredentials: root.userCreds,
+			TargetPath: nodeUnpublishVolume.targetPath,
+			Secrets:    root.userCreds,
 		}
 
 		for i := range args {
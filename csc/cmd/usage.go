@@ -75,8 +75,14 @@ func argName(cmd *cobra.Command) string {
 		controllerUnpublishVolumeCmd,
 		valVolCapsCmd,
 		nodePublishVolumeCmd,
-		nodeUnpublishVolumeCmd:
+		nodeUnpublishVolumeCmd,
+		controllerExpandVolumeCmd,
+		nodeExpandVolumeCmd:
 		return "VOLUME_ID [VOLUME_ID...]"
+	case deleteSnapshotCmd:
+		return "SNAPSHOT_ID [SNAPSHOT_ID...]"
+	case createSnapshotCmd:
+		return "SNAPSHOT_NAME"
 	case RootCmd, controllerCmd, identityCmd, nodeCmd:
 		return "CMD"
 		//case docCmd:
@@ -171,15 +177,15 @@ const volumeCapabilityDesc = `One or more volume capabilities may be specified u
             --cap MULTI_NODE_MULTI_WRITER,mount,xfs,uid=500,gid=500`
 
 const attribsDesc = `One or more key/value pairs may be specified to send with
-        the request as its VolumeAttributes field:
+        the request as its VolumeContext field:
 
             --attrib key1=val1,key2=val2 --attrib=key3=val3`
 
-const withRequiresCredsDesc = `Marks the request's UserCredentials field as required.
+const withRequiresCredsDesc = `Marks the request's Secrets field as required.
         Enabling this option also enables --with-spec-validation.`
 
-const withRequiresReqAttribsDesc = `Marks the request's VolumeAttributes field as required.
+const withRequiresReqAttribsDesc = `Marks the request's VolumeContext field as required.
         Enabling this option also enables --with-spec-validation.`
 
-const withRequiresRepAttribsDesc = `Marks the response's VolumeInfo.Attributes field as required.
+const withRequiresRepAttribsDesc = `Marks the response's Volume.VolumeContext field as required.
         Enabling this option also enables --with-spec-validation.`
@@ -0,0 +1,19 @@
+package gocsi
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrVolumeCapacityRangeRequired is returned when a
+	// ControllerExpandVolumeRequest is missing its CapacityRange field.
+	ErrVolumeCapacityRangeRequired = status.Error(
+		codes.InvalidArgument, "capacity range is required")
+
+	// ErrExpansionNotSupported is returned when a volume cannot be
+	// expanded, for example because the requested size is smaller than
+	// its current size.
+	ErrExpansionNotSupported = status.Error(
+		codes.OutOfRange, "volume expansion not supported for request")
+)
@@ -0,0 +1,18 @@
+package gocsi
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrSnapshotIDRequired is returned when a request is missing its
+	// SnapshotId field.
+	ErrSnapshotIDRequired = status.Error(
+		codes.InvalidArgument, "snapshot ID is required")
+
+	// ErrSourceVolumeIDRequired is returned when a request is missing
+	// its SourceVolumeId field.
+	ErrSourceVolumeIDRequired = status.Error(
+		codes.InvalidArgument, "source volume ID is required")
+)
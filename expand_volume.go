@@ -0,0 +1,30 @@
+package gocsi
+
+import (
+	"golang.org/x/net/context"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+// ExpandVolume invokes ControllerExpandVolume for volID with the
+// provided capacity range and secrets, returning the volume's new
+// capacity and whether the node must still expand the volume's
+// filesystem before it can be used at its new size.
+func ExpandVolume(
+	ctx context.Context,
+	client csi.ControllerClient,
+	volID string,
+	capRange *csi.CapacityRange,
+	secrets map[string]string) (int64, bool, error) {
+
+	rep, err := client.ControllerExpandVolume(
+		ctx, &csi.ControllerExpandVolumeRequest{
+			VolumeId:      volID,
+			CapacityRange: capRange,
+			Secrets:       secrets,
+		})
+	if err != nil {
+		return 0, false, err
+	}
+	return rep.CapacityBytes, rep.NodeExpansionRequired, nil
+}
@@ -2,14 +2,12 @@ package gocsi_test
 
 import (
 	"context"
-	"fmt"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 
-	"github.com/thecodeteam/gocsi"
-	"github.com/thecodeteam/gocsi/csi"
 	"github.com/thecodeteam/gocsi/mock/service"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
 )
 
 var _ = Describe("Identity", func() {
@@ -39,19 +37,10 @@ var _ = Describe("Identity", func() {
 			name          string
 			vendorVersion string
 			manifest      map[string]string
-			version       csi.Version
 		)
 		BeforeEach(func() {
-			version, err = gocsi.ParseVersion(CTest().ComponentTexts[3])
-			Ω(err).ShouldNot(HaveOccurred())
 			var res *csi.GetPluginInfoResponse
-			res, err = client.GetPluginInfo(ctx, &csi.GetPluginInfoRequest{
-				Version: &csi.Version{
-					Major: version.GetMajor(),
-					Minor: version.GetMinor(),
-					Patch: version.GetPatch(),
-				},
-			})
+			res, err = client.GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
 			if err == nil {
 				name = res.Name
 				vendorVersion = res.VendorVersion
@@ -63,52 +52,23 @@ var _ = Describe("Identity", func() {
 			vendorVersion = ""
 			manifest = nil
 		})
-		shouldBeValid := func() {
+		It("Should Be Valid", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(name).Should(Equal(service.Name))
 			Ω(vendorVersion).Should(Equal(service.VendorVersion))
 			Ω(manifest).Should(BeNil())
-		}
-		shouldNotBeValid := func() {
-			Ω(err).Should(ΣCM(
-				codes.InvalidArgument,
-				fmt.Sprintf("invalid request version: %s",
-					CTest().ComponentTexts[3])))
-
-		}
-		Context("With Request Version", func() {
-			Context("0.0.0", func() {
-				It("Should Not Be Valid", shouldNotBeValid)
-			})
-			Context("0.1.0", func() {
-				It("Should Be Valid", shouldBeValid)
-			})
-			Context("0.2.0", func() {
-				It("Should Be Valid", shouldBeValid)
-			})
-			Context("1.0.0", func() {
-				It("Should Be Valid", shouldBeValid)
-			})
-			Context("1.1.0", func() {
-				It("Should Be Valid", shouldBeValid)
-			})
-			Context("1.2.0", func() {
-				It("Should Not Be Valid", shouldNotBeValid)
-			})
 		})
 	})
 
-	Describe("GetSupportedVersions", func() {
+	Describe("GetPluginCapabilities", func() {
 		It("Should Be Valid", func() {
-			res, err := client.GetSupportedVersions(
-				ctx, &csi.GetSupportedVersionsRequest{})
+			res, err := client.GetPluginCapabilities(
+				ctx, &csi.GetPluginCapabilitiesRequest{})
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(res).ShouldNot(BeNil())
-			resVersions := res.SupportedVersions
-			Ω(resVersions).Should(HaveLen(len(mockSupportedVersions)))
-			for i, v := range resVersions {
-				Ω(*v).Should(Equal(mockSupportedVersions[i]))
-			}
+			Ω(res.Capabilities).Should(HaveLen(1))
+			Ω(res.Capabilities[0].GetService().Type).Should(
+				Equal(csi.PluginCapability_Service_CONTROLLER_SERVICE))
 		})
 	})
 })
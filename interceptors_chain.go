@@ -0,0 +1,192 @@
+package gocsi
+
+import (
+	"log"
+	"reflect"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewServerChain composes the provided stages into a single
+// UnaryServerInterceptor, invoking them in the order given. It replaces
+// hand-rolling calls to grpc.UnaryInterceptor with one interceptor per
+// concern: NewServerLogger, NewServerMetrics, WithRecovery,
+// WithSpecValidation, WithTimeout, and WithRequestID.
+func NewServerChain(
+	stages ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		chained := handler
+		for i := len(stages) - 1; i >= 0; i-- {
+			stage := stages[i]
+			next := chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return stage(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
+
+// WithLogging returns a NewServerChain stage that logs requests and
+// responses, equivalent to NewServerLogger(opts...).
+func WithLogging(opts ...LoggingOption) grpc.UnaryServerInterceptor {
+	return NewServerLogger(opts...)
+}
+
+// WithMetrics returns a NewServerChain stage that records Prometheus
+// metrics, equivalent to NewServerMetrics(opts...).
+func WithMetrics(opts ...MetricsOption) grpc.UnaryServerInterceptor {
+	return NewServerMetrics(opts...)
+}
+
+// WithRecovery returns a NewServerChain stage that recovers from panics
+// raised further down the chain, logging the panic and its stack trace
+// server-side and converting it into a generic codes.Internal error so
+// the caller never sees server-internal details over the wire.
+func WithRecovery() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (rep interface{}, err error) {
+
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic handling %s: %v\n%s",
+					info.FullMethod, r, debug.Stack())
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// WithTimeout returns a NewServerChain stage that fails the request
+// with codes.DeadlineExceeded if it has not completed within d.
+func WithTimeout(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		type result struct {
+			rep interface{}
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			rep, err := handler(ctx, req)
+			done <- result{rep, err}
+		}()
+
+		select {
+		case r := <-done:
+			return r.rep, r.err
+		case <-ctx.Done():
+			return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+		}
+	}
+}
+
+// WithRequestID returns a NewServerChain stage that assigns a unique,
+// monotonically increasing request ID to every request that does not
+// already carry one (for example, one propagated by the client via
+// contextWithIncomingRequestID), making it available via GetRequestID
+// to every later stage, including the logging interceptor.
+func WithRequestID() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		if _, ok := GetRequestID(ctx); !ok {
+			ctx = withRequestID(ctx, nextRequestID())
+		}
+		return handler(ctx, req)
+	}
+}
+
+var lastRequestID uint64
+
+func nextRequestID() uint64 {
+	return atomic.AddUint64(&lastRequestID, 1)
+}
+
+type requestIDContextKey struct{}
+
+// withRequestID stashes id on ctx so a later GetRequestID(ctx) call,
+// such as the one made by the logging interceptor, retrieves it.
+func withRequestID(ctx context.Context, id uint64) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// GetRequestID returns the request ID previously stored on ctx via
+// withRequestID, if any.
+func GetRequestID(ctx context.Context) (uint64, bool) {
+	id, ok := ctx.Value(requestIDContextKey{}).(uint64)
+	return id, ok
+}
+
+// RequiredField names a field that WithSpecValidation must verify is
+// set on incoming requests.
+type RequiredField string
+
+// Fields recognized by WithSpecValidation, matching the --with-requires-*
+// flags scattered through the csc cmd package.
+const (
+	FieldVolumeCapabilities RequiredField = "VolumeCapabilities"
+	FieldSecrets            RequiredField = "Secrets"
+	FieldVolumeContext      RequiredField = "VolumeContext"
+)
+
+// WithSpecValidation returns a NewServerChain stage that rejects, with
+// codes.InvalidArgument, any request missing one of the required
+// fields.
+func WithSpecValidation(fields ...RequiredField) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		for _, f := range fields {
+			if isZeroField(req, string(f)) {
+				return nil, status.Errorf(codes.InvalidArgument,
+					"required field missing: %s", f)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func isZeroField(req interface{}, name string) bool {
+	rv := reflect.ValueOf(req)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	fv := rv.FieldByName(name)
+	if !fv.IsValid() {
+		return false
+	}
+	return fv.IsZero()
+}
@@ -0,0 +1,188 @@
+package gocsi
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+func TestWithRecoveryConvertsPanicToInternal(t *testing.T) {
+	chain := NewServerChain(WithRecovery())
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	_, err := chain(context.Background(), &csi.CreateVolumeRequest{},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.Controller/CreateVolume"}, handler)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if got, want := status.Code(err), codes.Internal; got != want {
+		t.Errorf("code = %v, want %v", got, want)
+	}
+	if got := status.Convert(err).Message(); got == "boom" {
+		t.Errorf("panic value %q leaked to the caller", got)
+	}
+}
+
+func TestWithTimeoutExceeded(t *testing.T) {
+	chain := NewServerChain(WithTimeout(10 * time.Millisecond))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	_, err := chain(context.Background(), &csi.CreateVolumeRequest{},
+		&grpc.UnaryServerInfo{}, handler)
+	if got, want := status.Code(err), codes.DeadlineExceeded; got != want {
+		t.Errorf("code = %v, want %v", got, want)
+	}
+}
+
+func TestWithTimeoutOK(t *testing.T) {
+	chain := NewServerChain(WithTimeout(time.Second))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	rep, err := chain(context.Background(), &csi.CreateVolumeRequest{},
+		&grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := rep.(string), "ok"; got != want {
+		t.Errorf("rep = %q, want %q", got, want)
+	}
+}
+
+func TestWithSpecValidationRejectsMissingField(t *testing.T) {
+	chain := NewServerChain(WithSpecValidation(FieldVolumeCapabilities))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	_, err := chain(context.Background(), &csi.CreateVolumeRequest{},
+		&grpc.UnaryServerInfo{}, handler)
+	if got, want := status.Code(err), codes.InvalidArgument; got != want {
+		t.Errorf("code = %v, want %v", got, want)
+	}
+}
+
+func TestWithSpecValidationAllowsPresentField(t *testing.T) {
+	chain := NewServerChain(WithSpecValidation(FieldVolumeCapabilities))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	req := &csi.CreateVolumeRequest{
+		VolumeCapabilities: []*csi.VolumeCapability{{}},
+	}
+	rep, err := chain(context.Background(), req, &grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := rep.(string), "ok"; got != want {
+		t.Errorf("rep = %q, want %q", got, want)
+	}
+}
+
+func TestNewServerChainOrdering(t *testing.T) {
+	var calls []string
+	stage := func(name string) grpc.UnaryServerInterceptor {
+		return func(
+			ctx context.Context,
+			req interface{},
+			info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+
+			calls = append(calls, "before:"+name)
+			rep, err := handler(ctx, req)
+			calls = append(calls, "after:"+name)
+			return rep, err
+		}
+	}
+
+	chain := NewServerChain(stage("outer"), stage("inner"))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return nil, nil
+	}
+
+	if _, err := chain(
+		context.Background(), nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"before:outer", "before:inner", "handler", "after:inner", "after:outer"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestWithRequestIDAssignsWhenAbsent(t *testing.T) {
+	chain := NewServerChain(WithRequestID())
+
+	var sawID bool
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, sawID = GetRequestID(ctx)
+		return nil, nil
+	}
+
+	if _, err := chain(
+		context.Background(), nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sawID {
+		t.Fatal("expected a request ID to be assigned")
+	}
+}
+
+func TestWithRequestIDPreservesExisting(t *testing.T) {
+	chain := NewServerChain(WithRequestID())
+
+	ctx := withRequestID(context.Background(), 42)
+	var gotID uint64
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		gotID, _ = GetRequestID(ctx)
+		return nil, nil
+	}
+
+	if _, err := chain(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := gotID, uint64(42); got != want {
+		t.Errorf("request ID = %d, want %d", got, want)
+	}
+}
+
+func TestWithRecoveryPassesThroughNormalErrors(t *testing.T) {
+	chain := NewServerChain(WithRecovery())
+	wantErr := status.Error(codes.NotFound, "not found")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, wantErr
+	}
+
+	_, err := chain(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if !errors.Is(err, wantErr) && err.Error() != wantErr.Error() {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+}
@@ -2,22 +2,74 @@ package gocsi
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
+	"time"
 
+	"github.com/go-logfmt/logfmt"
+	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// RequestIDMetadataKey is the gRPC metadata key used to propagate a
+// request ID between a csc-style client and the plugin it talks to, so
+// that a single request ID can be correlated across process
+// boundaries.
+const RequestIDMetadataKey = "x-csi-request-id"
+
+// ProtoSanitizer redacts sensitive fields (such as CSI Secrets) from a
+// proto.Message before it is logged, returning a fmt.Stringer suitable
+// for printing in place of the raw message.
+type ProtoSanitizer func(msg proto.Message) fmt.Stringer
+
 // LoggingOption configures the logging interceptor.
 type LoggingOption func(*loggingOpts)
 
 type loggingOpts struct {
-	reqw io.Writer
-	repw io.Writer
+	reqw             io.Writer
+	repw             io.Writer
+	sanitizer        ProtoSanitizer
+	structuredFormat StructuredLoggingFormat
+}
+
+// WithProtoSanitizer is a LoggingOption that specifies the
+// ProtoSanitizer used to redact sensitive fields from logged requests
+// and responses. If not specified, the logging interceptor falls back
+// to its default behavior of dropping the Secrets field.
+func WithProtoSanitizer(s ProtoSanitizer) LoggingOption {
+	return func(o *loggingOpts) {
+		o.sanitizer = s
+	}
+}
+
+// StructuredLoggingFormat selects the encoding used by
+// WithStructuredLogging.
+type StructuredLoggingFormat string
+
+const (
+	// JSONLogging emits one JSON object per request/response.
+	JSONLogging StructuredLoggingFormat = "json"
+
+	// LogfmtLogging emits one logfmt line per request/response.
+	LogfmtLogging StructuredLoggingFormat = "logfmt"
+)
+
+// WithStructuredLogging is a LoggingOption that switches the logging
+// interceptor from its default, ad-hoc text output to one structured
+// record per request/response, with fields "method", "request_id",
+// "direction", "duration_ms", "code", "error", and "payload".
+func WithStructuredLogging(format StructuredLoggingFormat) LoggingOption {
+	return func(o *loggingOpts) {
+		o.structuredFormat = format
+	}
 }
 
 // WithRequestLogging is a LoggingOption that enables request logging
@@ -76,6 +128,8 @@ func (s *loggingInterceptor) handleServer(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler) (interface{}, error) {
 
+	ctx = contextWithIncomingRequestID(ctx)
+
 	return s.handle(ctx, info.FullMethod, req, func() (interface{}, error) {
 		return handler(ctx, req)
 	})
@@ -89,12 +143,47 @@ func (s *loggingInterceptor) handleClient(
 	invoker grpc.UnaryInvoker,
 	opts ...grpc.CallOption) error {
 
+	ctx = contextWithOutgoingRequestID(ctx)
+
 	_, err := s.handle(ctx, method, req, func() (interface{}, error) {
 		return rep, invoker(ctx, method, req, rep, cc, opts...)
 	})
 	return err
 }
 
+// contextWithIncomingRequestID reads RequestIDMetadataKey from ctx's
+// incoming gRPC metadata, if present, and stashes it on the context via
+// withRequestID so it flows through the rest of the request's handling
+// and logging the same way a request ID generated locally would.
+func contextWithIncomingRequestID(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	vals := md.Get(RequestIDMetadataKey)
+	if len(vals) == 0 {
+		return ctx
+	}
+	id, err := strconv.ParseUint(vals[0], 10, 64)
+	if err != nil {
+		return ctx
+	}
+	return withRequestID(ctx, id)
+}
+
+// contextWithOutgoingRequestID propagates the request ID already on ctx
+// (set via withRequestID or generated by a prior interceptor) into the
+// outgoing gRPC metadata under RequestIDMetadataKey, so a csc->plugin
+// call chain shares a single, correlatable request ID.
+func contextWithOutgoingRequestID(ctx context.Context) context.Context {
+	id, ok := GetRequestID(ctx)
+	if !ok {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(
+		ctx, RequestIDMetadataKey, strconv.FormatUint(id, 10))
+}
+
 func (s *loggingInterceptor) handle(
 	ctx context.Context,
 	method string,
@@ -107,15 +196,23 @@ func (s *loggingInterceptor) handle(
 		return next()
 	}
 
+	if s.opts.structuredFormat != "" {
+		return s.handleStructured(ctx, method, req, next)
+	}
+
 	w := &bytes.Buffer{}
 	reqID, reqIDOK := GetRequestID(ctx)
+	corrID, corrIDOK := CorrelationIDFromContext(ctx)
 
 	// Print the request
 	fmt.Fprintf(w, "%s: ", method)
 	if reqIDOK {
 		fmt.Fprintf(w, "REQ %04d", reqID)
 	}
-	rprintReqOrRep(w, req)
+	if corrIDOK {
+		fmt.Fprintf(w, " corr=%s", corrID)
+	}
+	s.rprintReqOrRep(w, req)
 	fmt.Fprintln(s.opts.reqw, w.String())
 
 	w.Reset()
@@ -132,6 +229,9 @@ func (s *loggingInterceptor) handle(
 	if reqIDOK {
 		fmt.Fprintf(w, "REP %04d", reqID)
 	}
+	if corrIDOK {
+		fmt.Fprintf(w, " corr=%s", corrID)
+	}
 
 	// Print the response error if it is set.
 	if failed != nil {
@@ -141,18 +241,134 @@ func (s *loggingInterceptor) handle(
 
 	// Print the response data if it is set.
 	if rep != nil {
-		rprintReqOrRep(w, rep)
+		s.rprintReqOrRep(w, rep)
 	}
 	fmt.Fprintln(s.opts.repw, w.String())
 
 	return
 }
 
+// structuredRecord is the shape of one request or response record
+// emitted by WithStructuredLogging.
+type structuredRecord struct {
+	Method        string      `json:"method"`
+	RequestID     uint64      `json:"request_id,omitempty"`
+	CorrelationID string      `json:"correlation_id,omitempty"`
+	Direction     string      `json:"direction"`
+	DurationMS    int64       `json:"duration_ms,omitempty"`
+	Code          string      `json:"code,omitempty"`
+	Error         string      `json:"error,omitempty"`
+	Payload       interface{} `json:"payload,omitempty"`
+}
+
+func (s *loggingInterceptor) handleStructured(
+	ctx context.Context,
+	method string,
+	req interface{},
+	next func() (interface{}, error)) (rep interface{}, failed error) {
+
+	reqID, _ := GetRequestID(ctx)
+	corrID, _ := CorrelationIDFromContext(ctx)
+
+	if s.opts.reqw != nil {
+		s.writeStructured(s.opts.reqw, structuredRecord{
+			Method:        method,
+			RequestID:     reqID,
+			CorrelationID: corrID,
+			Direction:     "request",
+			Payload:       s.structuredPayload(req),
+		})
+	}
+
+	start := time.Now()
+	rep, failed = next()
+	duration := time.Since(start)
+
+	if s.opts.repw == nil {
+		return
+	}
+
+	rec := structuredRecord{
+		Method:        method,
+		RequestID:     reqID,
+		CorrelationID: corrID,
+		Direction:     "response",
+		DurationMS:    duration.Nanoseconds() / int64(time.Millisecond),
+		Code:          status.Code(failed).String(),
+	}
+	if failed != nil {
+		rec.Error = failed.Error()
+	}
+	if rep != nil {
+		rec.Payload = s.structuredPayload(rep)
+	}
+	s.writeStructured(s.opts.repw, rec)
+
+	return
+}
+
+// structuredPayload renders obj the same way the text logger does,
+// running it through the configured ProtoSanitizer when one is set.
+func (s *loggingInterceptor) structuredPayload(obj interface{}) string {
+	w := &bytes.Buffer{}
+	s.rprintReqOrRep(w, obj)
+	return w.String()
+}
+
+func (s *loggingInterceptor) writeStructured(
+	w io.Writer, rec structuredRecord) {
+
+	switch s.opts.structuredFormat {
+	case JSONLogging:
+		enc := json.NewEncoder(w)
+		enc.Encode(rec)
+	case LogfmtLogging:
+		enc := logfmt.NewEncoder(w)
+		enc.EncodeKeyval("method", rec.Method)
+		if rec.RequestID != 0 {
+			enc.EncodeKeyval("request_id", rec.RequestID)
+		}
+		if rec.CorrelationID != "" {
+			enc.EncodeKeyval("correlation_id", rec.CorrelationID)
+		}
+		enc.EncodeKeyval("direction", rec.Direction)
+		if rec.DurationMS != 0 {
+			enc.EncodeKeyval("duration_ms", rec.DurationMS)
+		}
+		if rec.Code != "" {
+			enc.EncodeKeyval("code", rec.Code)
+		}
+		if rec.Error != "" {
+			enc.EncodeKeyval("error", rec.Error)
+		}
+		if rec.Payload != nil {
+			enc.EncodeKeyval("payload", rec.Payload)
+		}
+		enc.EndRecord()
+	}
+}
+
 var emptyValRX = regexp.MustCompile(
 	`^((?:)|(?:\[\])|(?:<nil>)|(?:map\[\]))$`)
 
 // rprintReqOrRep is used by the server-side interceptors that log
-// requests and responses.
+// requests and responses. When a ProtoSanitizer has been configured via
+// WithProtoSanitizer it is used to redact sensitive fields; otherwise
+// the interceptor falls back to its default behavior of dropping the
+// Secrets field.
+func (s *loggingInterceptor) rprintReqOrRep(w io.Writer, obj interface{}) {
+	if s.opts.sanitizer != nil {
+		if msg, ok := obj.(proto.Message); ok {
+			fmt.Fprintf(w, ": %s", s.opts.sanitizer(msg))
+			return
+		}
+	}
+	rprintReqOrRep(w, obj)
+}
+
+// rprintReqOrRep prints a request or response's fields, skipping the
+// Secrets field and any empty values. It remains the default behavior
+// when no ProtoSanitizer is configured.
 func rprintReqOrRep(w io.Writer, obj interface{}) {
 	rv := reflect.ValueOf(obj).Elem()
 	tv := rv.Type()
@@ -161,7 +377,7 @@ func rprintReqOrRep(w io.Writer, obj interface{}) {
 	printComma := false
 	for i := 0; i < nf; i++ {
 		name := tv.Field(i).Name
-		if name == "UserCredentials" {
+		if name == "Secrets" {
 			continue
 		}
 		sv := fmt.Sprintf("%v", rv.Field(i).Interface())
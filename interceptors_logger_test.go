@@ -0,0 +1,120 @@
+package gocsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+func TestStructuredLoggingJSON(t *testing.T) {
+	reqw := &bytes.Buffer{}
+	repw := &bytes.Buffer{}
+	logger := newLoggingInterceptor(
+		WithRequestLogging(reqw),
+		WithResponseLogging(repw),
+		WithStructuredLogging(JSONLogging))
+
+	ctx := withRequestID(context.Background(), 7)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &csi.CreateVolumeResponse{}, nil
+	}
+
+	_, err := logger.handleServer(
+		ctx, &csi.CreateVolumeRequest{Name: "test-volume"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.Controller/CreateVolume"}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var reqRec structuredRecord
+	if err := json.Unmarshal(reqw.Bytes(), &reqRec); err != nil {
+		t.Fatalf("decoding request record: %v", err)
+	}
+	if got, want := reqRec.Method, "/csi.Controller/CreateVolume"; got != want {
+		t.Errorf("request Method = %q, want %q", got, want)
+	}
+	if got, want := reqRec.RequestID, uint64(7); got != want {
+		t.Errorf("request RequestID = %d, want %d", got, want)
+	}
+	if got, want := reqRec.Direction, "request"; got != want {
+		t.Errorf("request Direction = %q, want %q", got, want)
+	}
+
+	var repRec structuredRecord
+	if err := json.Unmarshal(repw.Bytes(), &repRec); err != nil {
+		t.Fatalf("decoding response record: %v", err)
+	}
+	if got, want := repRec.Direction, "response"; got != want {
+		t.Errorf("response Direction = %q, want %q", got, want)
+	}
+	if got, want := repRec.Code, "OK"; got != want {
+		t.Errorf("response Code = %q, want %q", got, want)
+	}
+}
+
+func TestStructuredLoggingLogfmt(t *testing.T) {
+	reqw := &bytes.Buffer{}
+	logger := newLoggingInterceptor(
+		WithRequestLogging(reqw),
+		WithStructuredLogging(LogfmtLogging))
+
+	ctx := withRequestID(context.Background(), 9)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	if _, err := logger.handleServer(
+		ctx, &csi.CreateVolumeRequest{Name: "test-volume"},
+		&grpc.UnaryServerInfo{FullMethod: "/csi.Controller/CreateVolume"}, handler,
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := reqw.String()
+	for _, want := range []string{
+		"method=/csi.Controller/CreateVolume",
+		"request_id=9",
+		"direction=request",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("logfmt output %q missing %q", out, want)
+		}
+	}
+}
+
+func TestContextWithIncomingRequestIDPropagation(t *testing.T) {
+	md := metadata.Pairs(RequestIDMetadataKey, "123")
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	ctx = contextWithIncomingRequestID(ctx)
+
+	id, ok := GetRequestID(ctx)
+	if !ok {
+		t.Fatal("expected a request ID to be present on ctx")
+	}
+	if got, want := id, uint64(123); got != want {
+		t.Errorf("request ID = %d, want %d", got, want)
+	}
+}
+
+func TestContextWithOutgoingRequestIDPropagation(t *testing.T) {
+	ctx := withRequestID(context.Background(), 123)
+
+	ctx = contextWithOutgoingRequestID(ctx)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		t.Fatal("expected outgoing metadata to be present on ctx")
+	}
+	vals := md.Get(RequestIDMetadataKey)
+	if len(vals) != 1 || vals[0] != "123" {
+		t.Errorf("outgoing metadata %q = %v, want [123]", RequestIDMetadataKey, vals)
+	}
+}
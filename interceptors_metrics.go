@@ -0,0 +1,166 @@
+package gocsi
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsOption configures the metrics interceptor.
+type MetricsOption func(*metricsOpts)
+
+type metricsOpts struct {
+	registerer prometheus.Registerer
+}
+
+// WithRegisterer is a MetricsOption that specifies the Prometheus
+// Registerer used to register the interceptor's collectors. If not
+// specified, prometheus.DefaultRegisterer is used.
+func WithRegisterer(r prometheus.Registerer) MetricsOption {
+	return func(o *metricsOpts) {
+		o.registerer = r
+	}
+}
+
+type metricsInterceptor struct {
+	reqTotal    *prometheus.CounterVec
+	reqInFlight *prometheus.GaugeVec
+	reqDuration *prometheus.HistogramVec
+}
+
+// NewServerMetrics returns a new UnaryServerInterceptor that records
+// per-RPC counters, in-flight gauges, and latency histograms for the
+// CSI methods it intercepts.
+func NewServerMetrics(opts ...MetricsOption) grpc.UnaryServerInterceptor {
+	return newMetricsInterceptor("server", opts...).handleServer
+}
+
+// NewClientMetrics returns a new UnaryClientInterceptor that records
+// per-RPC counters, in-flight gauges, and latency histograms for the
+// CSI methods it intercepts.
+func NewClientMetrics(opts ...MetricsOption) grpc.UnaryClientInterceptor {
+	return newMetricsInterceptor("client", opts...).handleClient
+}
+
+func newMetricsInterceptor(
+	kind string, opts ...MetricsOption) *metricsInterceptor {
+
+	o := metricsOpts{registerer: prometheus.DefaultRegisterer}
+	for _, withOpts := range opts {
+		withOpts(&o)
+	}
+
+	i := &metricsInterceptor{
+		reqTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "csi",
+				Subsystem: kind,
+				Name:      "requests_total",
+				Help:      "The total number of CSI RPCs processed.",
+			},
+			[]string{"method", "code"}),
+		reqInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "csi",
+				Subsystem: kind,
+				Name:      "requests_in_flight",
+				Help:      "The number of CSI RPCs currently in flight.",
+			},
+			[]string{"method"}),
+		reqDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "csi",
+				Subsystem: kind,
+				Name:      "request_duration_seconds",
+				Help:      "The latency of CSI RPCs.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"method", "code"}),
+	}
+
+	o.registerer.MustRegister(i.reqTotal, i.reqInFlight, i.reqDuration)
+
+	return i
+}
+
+func (s *metricsInterceptor) handleServer(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+
+	return s.handle(info.FullMethod, func() (interface{}, error) {
+		return handler(ctx, req)
+	})
+}
+
+func (s *metricsInterceptor) handleClient(
+	ctx context.Context,
+	method string,
+	req, rep interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption) error {
+
+	_, err := s.handle(method, func() (interface{}, error) {
+		return rep, invoker(ctx, method, req, rep, cc, opts...)
+	})
+	return err
+}
+
+func (s *metricsInterceptor) handle(
+	method string,
+	next func() (interface{}, error)) (interface{}, error) {
+
+	s.reqInFlight.WithLabelValues(method).Inc()
+	defer s.reqInFlight.WithLabelValues(method).Dec()
+
+	start := time.Now()
+	rep, err := next()
+	duration := time.Since(start).Seconds()
+
+	code := status.Code(err)
+	s.reqTotal.WithLabelValues(method, code.String()).Inc()
+	s.reqDuration.WithLabelValues(method, code.String()).Observe(duration)
+
+	return rep, err
+}
+
+// ServeMetrics registers the Prometheus handler for the Registerer
+// configured via WithRegisterer (or prometheus.DefaultRegisterer, if
+// none is given) with an HTTP server listening on addr and returns a
+// function that stops it. This lets plugin authors who chain
+// NewServerMetrics/NewClientMetrics alongside NewServerLogger expose a
+// /metrics endpoint without wiring their own HTTP server.
+func ServeMetrics(addr string, opts ...MetricsOption) (stop func() error, err error) {
+	o := metricsOpts{registerer: prometheus.DefaultRegisterer}
+	for _, withOpts := range opts {
+		withOpts(&o)
+	}
+
+	gatherer, ok := o.registerer.(prometheus.Gatherer)
+	if !ok {
+		gatherer = prometheus.DefaultGatherer
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux}
+
+	go srv.Serve(lis)
+
+	return func() error {
+		return lis.Close()
+	}, nil
+}
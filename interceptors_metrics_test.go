@@ -0,0 +1,98 @@
+package gocsi
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewServerMetricsRecordsRequest(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	chain := NewServerChain(NewServerMetrics(WithRegisterer(reg)))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.Controller/CreateVolume"}
+
+	if _, err := chain(context.Background(), nil, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	total := findMetricValue(t, mfs, "csi_server_requests_total",
+		map[string]string{"method": info.FullMethod, "code": codes.OK.String()})
+	if got, want := total, 1.0; got != want {
+		t.Errorf("csi_server_requests_total = %v, want %v", got, want)
+	}
+}
+
+func TestNewServerMetricsRecordsErrorCode(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	chain := NewServerChain(NewServerMetrics(WithRegisterer(reg)))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "volume not found")
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.Controller/DeleteVolume"}
+
+	if _, err := chain(context.Background(), nil, info, handler); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	total := findMetricValue(t, mfs, "csi_server_requests_total",
+		map[string]string{"method": info.FullMethod, "code": codes.NotFound.String()})
+	if got, want := total, 1.0; got != want {
+		t.Errorf("csi_server_requests_total = %v, want %v", got, want)
+	}
+}
+
+// findMetricValue locates the counter value of the metric family named
+// and labeled as given, failing the test if it cannot be found.
+func findMetricValue(
+	t *testing.T,
+	mfs []*dto.MetricFamily,
+	name string,
+	labels map[string]string) float64 {
+
+	t.Helper()
+
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if metricLabelsMatch(m, labels) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	t.Fatalf("metric %s%v not found", name, labels)
+	return 0
+}
+
+func metricLabelsMatch(m *dto.Metric, labels map[string]string) bool {
+	if len(m.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, lp := range m.GetLabel() {
+		if labels[lp.GetName()] != lp.GetValue() {
+			return false
+		}
+	}
+	return true
+}
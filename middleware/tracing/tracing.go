@@ -0,0 +1,221 @@
+// Package tracing provides gRPC interceptors that generate or
+// propagate a correlation ID on every CSI RPC, and an opentracing
+// integration that turns each RPC into a span.
+package tracing
+
+import (
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/thecodeteam/gocsi"
+)
+
+// CorrelationIDMetadataKey is the gRPC metadata key used to propagate a
+// correlation ID between a csc-style client and the plugin it talks to.
+const CorrelationIDMetadataKey = "csi-correlation-id"
+
+var (
+	tracerMu sync.RWMutex
+	tracer   opentracing.Tracer
+)
+
+// SetTracer installs t as the opentracing.Tracer the interceptors in
+// this package use to start spans. If it is never called, spans are
+// not created, and the interceptors only handle correlation IDs.
+func SetTracer(t opentracing.Tracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	tracer = t
+}
+
+func getTracer() opentracing.Tracer {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return tracer
+}
+
+// generateCorrelationID returns a random 16-byte hex-encoded ID, used
+// when an incoming request does not already carry one.
+func generateCorrelationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// NewServerInterceptor returns a UnaryServerInterceptor that reads the
+// correlation ID from the incoming CorrelationIDMetadataKey metadata,
+// generating one if absent, and stashes it on the context via
+// gocsi.WithCorrelationID. If a tracer has been installed via
+// SetTracer, the RPC is also wrapped in a span tagged with the
+// request's volume-id/node-id, if present.
+func NewServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx = contextWithIncomingCorrelationID(ctx)
+
+		span, ctx := startSpan(ctx, info.FullMethod, req)
+		if span != nil {
+			defer span.Finish()
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// NewClientInterceptor returns a UnaryClientInterceptor that propagates
+// the correlation ID already on ctx (or generates one) into the
+// outgoing CorrelationIDMetadataKey metadata, so a csc->plugin call
+// chain shares a single, correlatable ID.
+func NewClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, rep interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption) error {
+
+		ctx = contextWithOutgoingCorrelationID(ctx)
+
+		span, ctx := startSpan(ctx, method, req)
+		if span != nil {
+			defer span.Finish()
+		}
+
+		return invoker(ctx, method, req, rep, cc, opts...)
+	}
+}
+
+// NewStreamServerInterceptor is the streaming counterpart of
+// NewServerInterceptor. It handles correlation IDs only; it does not
+// start a span.
+func NewStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+
+		ctx := contextWithIncomingCorrelationID(ss.Context())
+		return handler(srv, &correlatedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// NewStreamClientInterceptor is the streaming counterpart of
+// NewClientInterceptor. It handles correlation IDs only; it does not
+// start a span.
+func NewStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		ctx = contextWithOutgoingCorrelationID(ctx)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+type correlatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *correlatedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// contextWithIncomingCorrelationID reads CorrelationIDMetadataKey from
+// ctx's incoming gRPC metadata if present, generating a new correlation
+// ID otherwise, and stashes it on the context via gocsi.WithCorrelationID.
+func contextWithIncomingCorrelationID(ctx context.Context) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(CorrelationIDMetadataKey); len(vals) > 0 {
+			id = vals[0]
+		}
+	}
+	if id == "" {
+		id = generateCorrelationID()
+	}
+	return gocsi.WithCorrelationID(ctx, id)
+}
+
+// contextWithOutgoingCorrelationID propagates the correlation ID
+// already on ctx (set via gocsi.WithCorrelationID, or generated here)
+// into the outgoing gRPC metadata under CorrelationIDMetadataKey.
+func contextWithOutgoingCorrelationID(ctx context.Context) context.Context {
+	id, ok := gocsi.CorrelationIDFromContext(ctx)
+	if !ok {
+		id = generateCorrelationID()
+		ctx = gocsi.WithCorrelationID(ctx, id)
+	}
+	return metadata.AppendToOutgoingContext(ctx, CorrelationIDMetadataKey, id)
+}
+
+// startSpan starts a child span for method via the tracer installed
+// with SetTracer, tagging it with the request's volume-id/node-id when
+// present. It returns a nil span if no tracer has been installed.
+func startSpan(
+	ctx context.Context,
+	method string,
+	req interface{}) (opentracing.Span, context.Context) {
+
+	t := getTracer()
+	if t == nil {
+		return nil, ctx
+	}
+
+	span, ctx := opentracing.StartSpanFromContextWithTracer(ctx, t, method)
+	if volID, ok := stringField(req, "VolumeId"); ok {
+		span.SetTag("volume-id", volID)
+	}
+	if nodeID, ok := stringField(req, "NodeId"); ok {
+		span.SetTag("node-id", nodeID)
+	}
+	return span, ctx
+}
+
+// ChildSpan starts a child span named operation from the span on ctx,
+// via the tracer installed with SetTracer, for plugins that want to
+// record work narrower than a whole RPC (for example, the mock
+// service's CreateVolume/DeleteVolume/ControllerPublishVolume
+// handlers). It returns a nil span if no tracer has been installed.
+func ChildSpan(
+	ctx context.Context, operation string) (opentracing.Span, context.Context) {
+
+	t := getTracer()
+	if t == nil {
+		return nil, ctx
+	}
+	return opentracing.StartSpanFromContextWithTracer(ctx, t, operation)
+}
+
+// stringField returns the string value of req's field named name, if
+// req is a pointer to a struct with such a field.
+func stringField(req interface{}, name string) (string, bool) {
+	rv := reflect.ValueOf(req)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return "", false
+	}
+	fv := rv.Elem().FieldByName(name)
+	if !fv.IsValid() || fv.Kind() != reflect.String {
+		return "", false
+	}
+	return fv.String(), true
+}
@@ -0,0 +1,506 @@
+package service
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/thecodeteam/gocsi"
+	"github.com/thecodeteam/gocsi/middleware/tracing"
+)
+
+func (s *service) CreateVolume(
+	ctx context.Context,
+	req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+
+	span, ctx := tracing.ChildSpan(ctx, "service.CreateVolume")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	if req.Name == "" {
+		return nil, status.Error(
+			codes.InvalidArgument, "volume name is required")
+	}
+
+	var srcVolID, srcSnapID string
+	if vcs := req.VolumeContentSource; vcs != nil {
+		switch t := vcs.Type.(type) {
+		case *csi.VolumeContentSource_Volume:
+			srcVolID = t.Volume.VolumeId
+		case *csi.VolumeContentSource_Snapshot:
+			srcSnapID = t.Snapshot.SnapshotId
+		default:
+			return nil, status.Error(
+				codes.InvalidArgument,
+				"VolumeContentSource: only cloning from an existing volume "+
+					"or restoring from a snapshot is supported")
+		}
+	}
+	// srcID is the key under which this source is recorded in
+	// volSourcesByName, disambiguating a cloned volume from a
+	// snapshot restore so a repeated CreateVolume call for the same
+	// name but a different source is rejected rather than treated as
+	// idempotent.
+	srcID := srcVolID
+	if srcSnapID != "" {
+		srcID = "snapshot:" + srcSnapID
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if i, vol := s.findVolByNameNoLock(req.Name); i >= 0 {
+		if s.pendingCreates[req.Name] {
+			return nil, status.Error(
+				codes.Aborted, "an operation is already pending for "+req.Name)
+		}
+		if s.volSourcesByName[req.Name] != srcID {
+			return nil, status.Errorf(
+				codes.AlreadyExists,
+				"volume %s already exists with a different source", req.Name)
+		}
+		return &csi.CreateVolumeResponse{Volume: vol}, nil
+	}
+
+	s.pendingCreates[req.Name] = true
+	defer delete(s.pendingCreates, req.Name)
+
+	var capacity int64
+	if cr := req.CapacityRange; cr != nil {
+		capacity = cr.RequiredBytes
+		if cr.LimitBytes > 0 {
+			capacity = cr.LimitBytes
+		}
+	}
+
+	vol := s.newVolume(req.Name, capacity)
+
+	var srcVol *csi.Volume
+	switch {
+	case srcVolID != "":
+		if _, v := s.findVolNoLock(srcVolID); v != nil {
+			srcVol = v
+		} else {
+			return nil, status.Errorf(
+				codes.NotFound, "source volume %s does not exist", srcVolID)
+		}
+	case srcSnapID != "":
+		snap, ok := s.snapsByID[srcSnapID]
+		if !ok {
+			return nil, status.Errorf(
+				codes.NotFound, "snapshot %s does not exist", srcSnapID)
+		}
+		if _, v := s.findVolNoLock(snap.SourceVolumeId); v != nil {
+			srcVol = v
+		} else {
+			return nil, status.Errorf(
+				codes.NotFound, "source volume %s for snapshot %s does not exist",
+				snap.SourceVolumeId, srcSnapID)
+		}
+	}
+
+	if srcVol != nil {
+		if capacity == 0 {
+			capacity = srcVol.CapacityBytes
+		} else if capacity < srcVol.CapacityBytes {
+			return nil, status.Errorf(
+				codes.OutOfRange,
+				"requested capacity is smaller than source volume %s",
+				srcVol.VolumeId)
+		}
+		vol.CapacityBytes = capacity
+		for k, v := range srcVol.VolumeContext {
+			vol.VolumeContext[k] = v
+		}
+		vol.VolumeContext["name"] = req.Name
+		vol.ContentSource = req.VolumeContentSource
+	}
+
+	s.vols = append(s.vols, vol)
+	s.volSourcesByName[req.Name] = srcID
+
+	return &csi.CreateVolumeResponse{Volume: &vol}, nil
+}
+
+func (s *service) DeleteVolume(
+	ctx context.Context,
+	req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
+
+	span, ctx := tracing.ChildSpan(ctx, "service.DeleteVolume")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	if req.VolumeId == "" {
+		return nil, status.Error(
+			codes.InvalidArgument, "volume ID is required")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if i, _ := s.findVolNoLock(req.VolumeId); i >= 0 {
+		s.vols = append(s.vols[:i], s.vols[i+1:]...)
+	}
+
+	return &csi.DeleteVolumeResponse{}, nil
+}
+
+func (s *service) ListVolumes(
+	ctx context.Context,
+	req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	start := 0
+	if req.StartingToken != "" {
+		i, err := strconv.Atoi(req.StartingToken)
+		if err != nil || i < 0 || i > len(s.vols) {
+			return nil, status.Errorf(
+				codes.Aborted, "invalid starting token: %s", req.StartingToken)
+		}
+		start = i
+	}
+
+	end := len(s.vols)
+	var nextToken string
+	if req.MaxEntries > 0 && start+int(req.MaxEntries) < end {
+		end = start + int(req.MaxEntries)
+		nextToken = idStr(uint64(end))
+	}
+
+	entries := make([]*csi.ListVolumesResponse_Entry, 0, end-start)
+	for i := start; i < end; i++ {
+		v := s.vols[i]
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: &v,
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: s.publishedNodesByVolID[v.VolumeId],
+			},
+		})
+	}
+
+	return &csi.ListVolumesResponse{Entries: entries, NextToken: nextToken}, nil
+}
+
+func (s *service) ControllerPublishVolume(
+	ctx context.Context,
+	req *csi.ControllerPublishVolumeRequest,
+) (*csi.ControllerPublishVolumeResponse, error) {
+
+	span, ctx := tracing.ChildSpan(ctx, "service.ControllerPublishVolume")
+	if span != nil {
+		defer span.Finish()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	i, vol := s.findVolNoLock(req.VolumeId)
+	if i < 0 {
+		return nil, status.Error(codes.NotFound, "volume not found")
+	}
+	if vol.VolumeContext == nil {
+		vol.VolumeContext = map[string]string{}
+	}
+	vol.VolumeContext[devPathKey] = "/dev/mock"
+
+	published := s.publishedNodesByVolID[vol.VolumeId]
+	if !containsString(published, req.NodeId) {
+		s.publishedNodesByVolID[vol.VolumeId] = append(published, req.NodeId)
+	}
+
+	return &csi.ControllerPublishVolumeResponse{
+		PublishContext: map[string]string{"device": "/dev/mock"},
+	}, nil
+}
+
+func (s *service) ControllerUnpublishVolume(
+	ctx context.Context,
+	req *csi.ControllerUnpublishVolumeRequest,
+) (*csi.ControllerUnpublishVolumeResponse, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	if i, vol := s.findVolNoLock(req.VolumeId); i >= 0 {
+		if req.NodeId == "" {
+			delete(s.publishedNodesByVolID, vol.VolumeId)
+		} else {
+			s.publishedNodesByVolID[vol.VolumeId] = removeString(
+				s.publishedNodesByVolID[vol.VolumeId], req.NodeId)
+		}
+		if len(s.publishedNodesByVolID[vol.VolumeId]) == 0 {
+			delete(vol.VolumeContext, devPathKey)
+		}
+	}
+
+	return &csi.ControllerUnpublishVolumeResponse{}, nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(ss []string, s string) []string {
+	out := ss[:0]
+	for _, v := range ss {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (s *service) ValidateVolumeCapabilities(
+	ctx context.Context,
+	req *csi.ValidateVolumeCapabilitiesRequest,
+) (*csi.ValidateVolumeCapabilitiesResponse, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	if i, _ := s.findVolNoLock(req.VolumeId); i < 0 {
+		return nil, status.Error(codes.NotFound, "volume not found")
+	}
+
+	return &csi.ValidateVolumeCapabilitiesResponse{
+		Confirmed: &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
+			VolumeContext:      req.VolumeContext,
+			VolumeCapabilities: req.VolumeCapabilities,
+			Parameters:         req.Parameters,
+		},
+	}, nil
+}
+
+func (s *service) GetCapacity(
+	ctx context.Context,
+	req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
+
+	return &csi.GetCapacityResponse{
+		AvailableCapacity: 1.074e+12,
+	}, nil
+}
+
+func (s *service) ControllerGetCapabilities(
+	ctx context.Context,
+	req *csi.ControllerGetCapabilitiesRequest,
+) (*csi.ControllerGetCapabilitiesResponse, error) {
+
+	return &csi.ControllerGetCapabilitiesResponse{
+		Capabilities: []*csi.ControllerServiceCapability{
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_LIST_VOLUMES),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_GET_CAPACITY),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_EXPAND_VOLUME),
+			newControllerCap(
+				csi.ControllerServiceCapability_RPC_CLONE_VOLUME),
+		},
+	}, nil
+}
+
+func newControllerCap(
+	c csi.ControllerServiceCapability_RPC_Type,
+) *csi.ControllerServiceCapability {
+
+	return &csi.ControllerServiceCapability{
+		Type: &csi.ControllerServiceCapability_Rpc{
+			Rpc: &csi.ControllerServiceCapability_RPC{Type: c},
+		},
+	}
+}
+
+func (s *service) CreateSnapshot(
+	ctx context.Context,
+	req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+
+	if req.SourceVolumeId == "" {
+		return nil, gocsi.ErrSourceVolumeIDRequired
+	}
+	if req.Name == "" {
+		return nil, status.Error(
+			codes.InvalidArgument, "snapshot name is required")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if i, _ := s.findVolNoLock(req.SourceVolumeId); i < 0 {
+		return nil, status.Error(codes.NotFound, "source volume not found")
+	}
+
+	pendingKey := req.SourceVolumeId + "/" + req.Name
+
+	if existing := s.findSnapByNameNoLock(
+		req.SourceVolumeId, req.Name); existing != nil {
+
+		if s.pendingSnapshotCreates[pendingKey] {
+			return nil, status.Error(
+				codes.Aborted, "an operation is already pending for "+req.Name)
+		}
+		return &csi.CreateSnapshotResponse{Snapshot: existing}, nil
+	}
+
+	s.pendingSnapshotCreates[pendingKey] = true
+	defer delete(s.pendingSnapshotCreates, pendingKey)
+
+	s.nextSnapID++
+	snap := csi.Snapshot{
+		SnapshotId:     idStr(s.nextSnapID),
+		SourceVolumeId: req.SourceVolumeId,
+		ReadyToUse:     true,
+	}
+
+	s.snapsByID[snap.SnapshotId] = snap
+	s.snapsByVolID[req.SourceVolumeId] = append(
+		s.snapsByVolID[req.SourceVolumeId], snap)
+	if s.snapNamesByVolID[req.SourceVolumeId] == nil {
+		s.snapNamesByVolID[req.SourceVolumeId] = map[string]string{}
+	}
+	s.snapNamesByVolID[req.SourceVolumeId][req.Name] = snap.SnapshotId
+
+	return &csi.CreateSnapshotResponse{Snapshot: &snap}, nil
+}
+
+func (s *service) DeleteSnapshot(
+	ctx context.Context,
+	req *csi.DeleteSnapshotRequest) (*csi.DeleteSnapshotResponse, error) {
+
+	if req.SnapshotId == "" {
+		return nil, gocsi.ErrSnapshotIDRequired
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	snap, ok := s.snapsByID[req.SnapshotId]
+	if !ok {
+		return &csi.DeleteSnapshotResponse{}, nil
+	}
+	delete(s.snapsByID, req.SnapshotId)
+
+	snaps := s.snapsByVolID[snap.SourceVolumeId]
+	for i := range snaps {
+		if snaps[i].SnapshotId == req.SnapshotId {
+			s.snapsByVolID[snap.SourceVolumeId] = append(
+				snaps[:i], snaps[i+1:]...)
+			break
+		}
+	}
+
+	return &csi.DeleteSnapshotResponse{}, nil
+}
+
+func (s *service) ListSnapshots(
+	ctx context.Context,
+	req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	var snaps []csi.Snapshot
+	switch {
+	case req.SnapshotId != "":
+		if snap, ok := s.snapsByID[req.SnapshotId]; ok {
+			snaps = []csi.Snapshot{snap}
+		}
+	case req.SourceVolumeId != "":
+		snaps = s.snapsByVolID[req.SourceVolumeId]
+	default:
+		// s.vols preserves creation order; walking it instead of
+		// ranging over the snapsByVolID map keeps the result order
+		// stable across the calls PageAllSnapshots makes to follow a
+		// NextToken.
+		for i := range s.vols {
+			snaps = append(snaps, s.snapsByVolID[s.vols[i].VolumeId]...)
+		}
+	}
+
+	start := 0
+	if req.StartingToken != "" {
+		i, err := strconv.Atoi(req.StartingToken)
+		if err != nil || i < 0 || i > len(snaps) {
+			return nil, status.Errorf(
+				codes.Aborted, "invalid starting token: %s", req.StartingToken)
+		}
+		start = i
+	}
+
+	end := len(snaps)
+	var nextToken string
+	if req.MaxEntries > 0 && start+int(req.MaxEntries) < end {
+		end = start + int(req.MaxEntries)
+		nextToken = idStr(uint64(end))
+	}
+
+	entries := make([]*csi.ListSnapshotsResponse_Entry, 0, end-start)
+	for i := start; i < end; i++ {
+		snap := snaps[i]
+		entries = append(entries, &csi.ListSnapshotsResponse_Entry{Snapshot: &snap})
+	}
+
+	return &csi.ListSnapshotsResponse{Entries: entries, NextToken: nextToken}, nil
+}
+
+func (s *service) ControllerExpandVolume(
+	ctx context.Context,
+	req *csi.ControllerExpandVolumeRequest,
+) (*csi.ControllerExpandVolumeResponse, error) {
+
+	if req.CapacityRange == nil {
+		return nil, gocsi.ErrVolumeCapacityRangeRequired
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	i, vol := s.findVolNoLock(req.VolumeId)
+	if i < 0 {
+		return nil, status.Error(codes.NotFound, "volume not found")
+	}
+
+	newSize := req.CapacityRange.RequiredBytes
+	if l := req.CapacityRange.LimitBytes; l > 0 && newSize > l {
+		return nil, gocsi.ErrExpansionNotSupported
+	}
+
+	if newSize <= vol.CapacityBytes {
+		// Treat a no-op/shrink request for a volume already at the
+		// requested size as an idempotent success.
+		if newSize == vol.CapacityBytes {
+			return &csi.ControllerExpandVolumeResponse{
+				CapacityBytes: vol.CapacityBytes,
+			}, nil
+		}
+		return nil, gocsi.ErrExpansionNotSupported
+	}
+
+	vol.CapacityBytes = newSize
+
+	return &csi.ControllerExpandVolumeResponse{
+		CapacityBytes:         vol.CapacityBytes,
+		NodeExpansionRequired: true,
+	}, nil
+}
@@ -0,0 +1,145 @@
+// Package service provides a mock, in-memory implementation of the
+// CSI v1.x Controller, Identity, and Node services used to exercise
+// gocsi's helpers and interceptors in the project's Ginkgo test
+// suites.
+package service
+
+import (
+	"path"
+	"strconv"
+	"sync"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+const (
+	// Name is the name of this CSI plug-in.
+	Name = "mock"
+
+	// VendorVersion is the version returned by GetPluginInfo.
+	VendorVersion = "0.0.0"
+)
+
+func idStr(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+// devPathKey is the Volume attribute set by ControllerPublishVolume to
+// record the device path a volume was published with.
+var devPathKey = path.Join(Name, "dev")
+
+// Service is the interface implemented by the mock CSI plug-in,
+// combining the Controller, Identity, and Node services the gRPC
+// server registers.
+type Service interface {
+	csi.ControllerServer
+	csi.IdentityServer
+	csi.NodeServer
+}
+
+type service struct {
+	sync.Mutex
+
+	nextVolID  uint64
+	nextSnapID uint64
+
+	vols []csi.Volume
+
+	// volSourcesByName records the source volume ID a volume was cloned
+	// from, or "snapshot:" plus the snapshot ID it was restored from, if
+	// any, when it was created, keyed by volume name, so a
+	// repeated CreateVolume call for the same name but a different
+	// VolumeContentSource can be rejected as a name conflict instead of
+	// treated as idempotent.
+	volSourcesByName map[string]string
+
+	// snapsByVolID indexes snapshots by the ID of the volume they were
+	// taken from, so ListSnapshots can honor the SourceVolumeId filter
+	// without a linear scan across every snapshot.
+	snapsByVolID map[string][]csi.Snapshot
+	snapsByID    map[string]csi.Snapshot
+
+	// snapNamesByVolID tracks the name a snapshot was created with,
+	// keyed by source volume ID, so a repeated CreateSnapshot call for
+	// the same name is recognized as idempotent rather than creating a
+	// second snapshot.
+	snapNamesByVolID map[string]map[string]string
+
+	// publishedNodesByVolID tracks the node IDs a volume is currently
+	// published to, keyed by volume ID. The CSI v1.x Volume message
+	// has no field for this, so ListVolumes reports it per-entry via
+	// ListVolumesResponse_Entry.Status.
+	publishedNodesByVolID map[string][]string
+
+	// pendingCreates tracks volume names with a CreateVolume in
+	// flight. CreateVolume holds the service lock for its whole run and
+	// removes its entry before releasing it, so no other call ever sees
+	// an entry: a duplicate call for the same name waits and then
+	// returns the existing volume, and the codes.Aborted branch that
+	// checks this map is never taken.
+	pendingCreates map[string]bool
+
+	// pendingSnapshotCreates mirrors pendingCreates, but for
+	// CreateSnapshot, keyed by source volume ID + snapshot name; it is
+	// likewise never seen set by another call.
+	pendingSnapshotCreates map[string]bool
+}
+
+// New returns a new Service, seeded with three volumes so the existing
+// test suite's "Should Be Valid" / HaveLen(3) expectations keep
+// passing.
+func New() Service {
+	s := &service{
+		volSourcesByName:       map[string]string{},
+		snapsByVolID:           map[string][]csi.Snapshot{},
+		snapsByID:              map[string]csi.Snapshot{},
+		snapNamesByVolID:       map[string]map[string]string{},
+		publishedNodesByVolID:  map[string][]string{},
+		pendingCreates:         map[string]bool{},
+		pendingSnapshotCreates: map[string]bool{},
+	}
+	s.vols = []csi.Volume{
+		s.newVolume("Mock Volume 1", 1.074e+10),
+		s.newVolume("Mock Volume 2", 1.074e+10),
+		s.newVolume("Mock Volume 3", 1.074e+10),
+	}
+	return s
+}
+
+func (s *service) newVolume(name string, capacity int64) csi.Volume {
+	s.nextVolID++
+	return csi.Volume{
+		VolumeId:      idStr(s.nextVolID),
+		CapacityBytes: capacity,
+		VolumeContext: map[string]string{"name": name},
+	}
+}
+
+func (s *service) findVolNoLock(id string) (int, *csi.Volume) {
+	for i := range s.vols {
+		if s.vols[i].VolumeId == id {
+			return i, &s.vols[i]
+		}
+	}
+	return -1, nil
+}
+
+func (s *service) findVolByNameNoLock(name string) (int, *csi.Volume) {
+	for i := range s.vols {
+		if s.vols[i].VolumeContext["name"] == name {
+			return i, &s.vols[i]
+		}
+	}
+	return -1, nil
+}
+
+func (s *service) findSnapByNameNoLock(
+	srcVolID, name string) *csi.Snapshot {
+
+	id, ok := s.snapNamesByVolID[srcVolID][name]
+	if !ok {
+		return nil
+	}
+	snap := s.snapsByID[id]
+	return &snap
+}
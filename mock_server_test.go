@@ -0,0 +1,61 @@
+package gocsi_test
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+
+	"github.com/thecodeteam/gocsi"
+	"github.com/thecodeteam/gocsi/middleware/tracing"
+	"github.com/thecodeteam/gocsi/mock/service"
+)
+
+// startMockServer starts the mock CSI plug-in behind an in-process
+// bufconn listener and returns a ClientConn dialed to it, a function
+// that stops the server, and any error encountered doing so. Every
+// spec in this package drives the mock service through the returned
+// connection.
+//
+// The server and client chains install tracing.NewServerInterceptor/
+// NewClientInterceptor, so a correlation ID is attributed to every
+// RPC on the server side. The ID is generated inside the client
+// interceptor; the specs in this package do not read it.
+func startMockServer(ctx context.Context) (*grpc.ClientConn, func(), error) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	svr := grpc.NewServer(
+		grpc.UnaryInterceptor(gocsi.NewServerChain(
+			tracing.NewServerInterceptor(),
+			gocsi.WithRequestID(),
+			gocsi.WithRecovery(),
+		)))
+	svc := service.New()
+	csi.RegisterControllerServer(svr, svc)
+	csi.RegisterIdentityServer(svr, svc)
+	csi.RegisterNodeServer(svr, svc)
+
+	go svr.Serve(lis)
+
+	gclient, err := grpc.DialContext(
+		ctx, "bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(tracing.NewClientInterceptor()))
+	if err != nil {
+		svr.Stop()
+		return nil, nil, err
+	}
+
+	stop := func() {
+		gclient.Close()
+		svr.Stop()
+	}
+
+	return gclient, stop, nil
+}
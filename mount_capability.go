@@ -0,0 +1,22 @@
+package gocsi
+
+import csi "github.com/container-storage-interface/spec/lib/go/csi"
+
+// NewMountCapability returns a new VolumeCapability for a volume
+// accessed via a mounted filesystem with the given access mode,
+// filesystem type, and mount flags.
+func NewMountCapability(
+	am csi.VolumeCapability_AccessMode_Mode,
+	fsType string,
+	mountFlags ...string) *csi.VolumeCapability {
+
+	return &csi.VolumeCapability{
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: am},
+		AccessType: &csi.VolumeCapability_Mount{
+			Mount: &csi.VolumeCapability_MountVolume{
+				FsType:     fsType,
+				MountFlags: mountFlags,
+			},
+		},
+	}
+}
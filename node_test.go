@@ -0,0 +1,78 @@
+package gocsi_test
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+var _ = Describe("Node", func() {
+	var (
+		err      error
+		stopMock func()
+		ctx      context.Context
+		gclient  *grpc.ClientConn
+		client   csi.NodeClient
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		gclient, stopMock, err = startMockServer(ctx)
+		Ω(err).ShouldNot(HaveOccurred())
+		client = csi.NewNodeClient(gclient)
+	})
+	AfterEach(func() {
+		ctx = nil
+		gclient.Close()
+		gclient = nil
+		client = nil
+		stopMock()
+	})
+
+	Describe("NodeExpandVolume", func() {
+		var (
+			req *csi.NodeExpandVolumeRequest
+			rep *csi.NodeExpandVolumeResponse
+		)
+		BeforeEach(func() {
+			req = &csi.NodeExpandVolumeRequest{
+				VolumeId:   "1",
+				VolumePath: "/mnt/vol1",
+			}
+		})
+		AfterEach(func() {
+			req = nil
+			rep = nil
+		})
+		JustBeforeEach(func() {
+			rep, err = client.NodeExpandVolume(ctx, req)
+		})
+		Context("Normal Expand", func() {
+			It("Should Be Valid", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(rep).ShouldNot(BeNil())
+			})
+		})
+		Context("Missing VolumeId", func() {
+			BeforeEach(func() {
+				req.VolumeId = ""
+			})
+			It("Should Be InvalidArgument", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+		Context("Missing VolumePath", func() {
+			BeforeEach(func() {
+				req.VolumePath = ""
+			})
+			It("Should Be InvalidArgument", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(status.Code(err)).Should(Equal(codes.InvalidArgument))
+			})
+		})
+	})
+})
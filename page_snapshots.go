@@ -0,0 +1,44 @@
+package gocsi
+
+import (
+	"golang.org/x/net/context"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+// PageAllSnapshots pages through every snapshot a ListSnapshots call
+// against client returns, following the response's NextToken until it
+// is exhausted, and streams the results back over the returned
+// channels. It mirrors PageAllVolumes.
+func PageAllSnapshots(
+	ctx context.Context,
+	client csi.ControllerClient,
+	req csi.ListSnapshotsRequest) (<-chan csi.Snapshot, <-chan error) {
+
+	csnap := make(chan csi.Snapshot)
+	cerr := make(chan error, 1)
+
+	go func() {
+		defer close(csnap)
+		defer close(cerr)
+
+		for {
+			rep, err := client.ListSnapshots(ctx, &req)
+			if err != nil {
+				cerr <- err
+				return
+			}
+			for _, e := range rep.Entries {
+				if e.Snapshot != nil {
+					csnap <- *e.Snapshot
+				}
+			}
+			if rep.NextToken == "" {
+				return
+			}
+			req.StartingToken = rep.NextToken
+		}
+	}()
+
+	return csnap, cerr
+}
@@ -0,0 +1,137 @@
+package gocsi
+
+import (
+	"golang.org/x/net/context"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+// PageVolumesOption configures PageVolumes and PageAllVolumes.
+type PageVolumesOption func(*pageVolumesOpts)
+
+type pageVolumesOpts struct {
+	req                 csi.ListVolumesRequest
+	volumeIDFilter      string
+	publishedNodeFilter string
+}
+
+// WithMaxEntries sets the max_entries field of the ListVolumes
+// requests issued by PageVolumes/PageAllVolumes, bounding the size of
+// each page returned by the server.
+func WithMaxEntries(n uint32) PageVolumesOption {
+	return func(o *pageVolumesOpts) {
+		o.req.MaxEntries = n
+	}
+}
+
+// WithStartingToken sets the starting_token field of the first
+// ListVolumes request issued by PageVolumes/PageAllVolumes, resuming a
+// paging operation from a token returned by an earlier call.
+func WithStartingToken(token string) PageVolumesOption {
+	return func(o *pageVolumesOpts) {
+		o.req.StartingToken = token
+	}
+}
+
+// WithVolumeIDFilter restricts PageVolumes/PageAllVolumes to the volume
+// with the given ID. The filter is applied on the client side, since
+// ListVolumesRequest has no volume ID field to send to the plugin.
+func WithVolumeIDFilter(volumeID string) PageVolumesOption {
+	return func(o *pageVolumesOpts) {
+		o.volumeIDFilter = volumeID
+	}
+}
+
+// WithPublishedNodeFilter restricts PageVolumes/PageAllVolumes to
+// volumes published to the given node ID. The filter is applied on the
+// client side, since ListVolumesRequest has no published-node field to
+// send to the plugin.
+func WithPublishedNodeFilter(nodeID string) PageVolumesOption {
+	return func(o *pageVolumesOpts) {
+		o.publishedNodeFilter = nodeID
+	}
+}
+
+func (o *pageVolumesOpts) matches(e *csi.ListVolumesResponse_Entry) bool {
+	if o.volumeIDFilter != "" && e.Volume.VolumeId != o.volumeIDFilter {
+		return false
+	}
+	if o.publishedNodeFilter != "" {
+		var published bool
+		if e.Status != nil {
+			for _, n := range e.Status.PublishedNodeIds {
+				if n == o.publishedNodeFilter {
+					published = true
+					break
+				}
+			}
+		}
+		if !published {
+			return false
+		}
+	}
+	return true
+}
+
+// PageVolumes issues a single ListVolumes call against client and
+// returns the entries it reports that match opts, along with the
+// response's NextToken so the caller can checkpoint its place and
+// resume paging later with WithStartingToken.
+func PageVolumes(
+	ctx context.Context,
+	client csi.ControllerClient,
+	opts ...PageVolumesOption) ([]csi.ListVolumesResponse_Entry, string, error) {
+
+	o := &pageVolumesOpts{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rep, err := client.ListVolumes(ctx, &o.req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var entries []csi.ListVolumesResponse_Entry
+	for _, e := range rep.Entries {
+		if e.Volume != nil && o.matches(e) {
+			entries = append(entries, *e)
+		}
+	}
+
+	return entries, rep.NextToken, nil
+}
+
+// PageAllVolumes pages through every volume entry known to client that
+// matches opts, following each response's NextToken until it is
+// exhausted, and streams the results back over the returned channels.
+func PageAllVolumes(
+	ctx context.Context,
+	client csi.ControllerClient,
+	opts ...PageVolumesOption) (<-chan csi.ListVolumesResponse_Entry, <-chan error) {
+
+	cvol := make(chan csi.ListVolumesResponse_Entry)
+	cerr := make(chan error, 1)
+
+	go func() {
+		defer close(cvol)
+		defer close(cerr)
+
+		for {
+			entries, nextToken, err := PageVolumes(ctx, client, opts...)
+			if err != nil {
+				cerr <- err
+				return
+			}
+			for _, e := range entries {
+				cvol <- e
+			}
+			if nextToken == "" {
+				return
+			}
+			opts = append(opts, WithStartingToken(nextToken))
+		}
+	}()
+
+	return cvol, cerr
+}
@@ -0,0 +1,72 @@
+// Package protosanitizer provides a gocsi.ProtoSanitizer implementation
+// for CSI 1.x messages that redacts the known secret-bearing fields
+// introduced after CSI 0.x (Secrets, NodePublishSecrets, and similar),
+// rather than only the top-level Secrets field gocsi's default
+// sanitizer drops.
+package protosanitizer
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// secretFieldRX matches the struct field names CSI 1.x uses for
+// secret-bearing maps across its request/response messages.
+var secretFieldRX = regexp.MustCompile(`(?:^|.*)Secrets$`)
+
+// StripSecrets returns a fmt.Stringer that formats msg with any field
+// whose name matches a known CSI secret field replaced with
+// "***stripped***". It is meant to be installed via
+// gocsi.WithProtoSanitizer for plugins built against the CSI 1.x spec,
+// where secrets may appear in several different messages
+// (CreateVolumeRequest.Secrets, NodeStageVolumeRequest.Secrets,
+// NodePublishVolumeRequest.Secrets, and so on) instead of the single
+// UserCredentials field used by CSI 0.x.
+func StripSecrets(msg proto.Message) fmt.Stringer {
+	return &stripSecrets{msg: msg}
+}
+
+type stripSecrets struct {
+	msg proto.Message
+}
+
+func (s *stripSecrets) String() string {
+	if s.msg == nil {
+		return "null"
+	}
+
+	rv := reflect.ValueOf(s.msg)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	tv := rv.Type()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s{", tv.Name())
+
+	printed := false
+	for i := 0; i < tv.NumField(); i++ {
+		name := tv.Field(i).Name
+		if strings.HasPrefix(name, "XXX_") {
+			continue
+		}
+		if printed {
+			b.WriteString(", ")
+		}
+		printed = true
+
+		fmt.Fprintf(&b, "%s:", name)
+		if secretFieldRX.MatchString(name) {
+			b.WriteString("***stripped***")
+			continue
+		}
+		fmt.Fprintf(&b, "%v", rv.Field(i).Interface())
+	}
+
+	b.WriteString("}")
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package protosanitizer
+
+import (
+	"strings"
+	"testing"
+
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+func TestStripSecretsRedactsSecretFields(t *testing.T) {
+	req := &csi.CreateVolumeRequest{
+		Name:    "test-volume",
+		Secrets: map[string]string{"password": "hunter2"},
+	}
+
+	s := StripSecrets(req).String()
+
+	if strings.Contains(s, "hunter2") {
+		t.Errorf("secret value leaked into sanitized output: %s", s)
+	}
+	if !strings.Contains(s, "Secrets:***stripped***") {
+		t.Errorf("expected Secrets to be replaced with ***stripped***, got: %s", s)
+	}
+}
+
+func TestStripSecretsKeepsOtherFields(t *testing.T) {
+	req := &csi.CreateVolumeRequest{
+		Name:    "test-volume",
+		Secrets: map[string]string{"password": "hunter2"},
+	}
+
+	s := StripSecrets(req).String()
+
+	if !strings.Contains(s, "Name:test-volume") {
+		t.Errorf("expected Name field to be printed unredacted, got: %s", s)
+	}
+}
+
+func TestStripSecretsNilMessage(t *testing.T) {
+	if got, want := StripSecrets(nil).String(), "null"; got != want {
+		t.Errorf("StripSecrets(nil) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,33 @@
+package gocsi
+
+import csi "github.com/container-storage-interface/spec/lib/go/csi"
+
+// NewSnapshotSourceVolumeContentSource returns a VolumeContentSource
+// that requests a new volume be restored from the snapshot identified
+// by snapshotID, for use as CreateVolumeRequest.VolumeContentSource.
+func NewSnapshotSourceVolumeContentSource(
+	snapshotID string) *csi.VolumeContentSource {
+
+	return &csi.VolumeContentSource{
+		Type: &csi.VolumeContentSource_Snapshot{
+			Snapshot: &csi.VolumeContentSource_SnapshotSource{
+				SnapshotId: snapshotID,
+			},
+		},
+	}
+}
+
+// NewVolumeSourceVolumeContentSource returns a VolumeContentSource that
+// requests a new volume be cloned from the volume identified by
+// sourceVolumeID, for use as CreateVolumeRequest.VolumeContentSource.
+func NewVolumeSourceVolumeContentSource(
+	sourceVolumeID string) *csi.VolumeContentSource {
+
+	return &csi.VolumeContentSource{
+		Type: &csi.VolumeContentSource_Volume{
+			Volume: &csi.VolumeContentSource_VolumeSource{
+				VolumeId: sourceVolumeID,
+			},
+		},
+	}
+}